@@ -0,0 +1,283 @@
+package astra
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+const DefaultAnnotationPrefix = "@"
+
+var (
+	errUnterminatedQuote = errors.New("unterminated quoted string")
+	errUnterminatedList  = errors.New("unterminated list")
+)
+
+// AnnotationParser parses annotations from comments of entities, e.g.
+//
+//	// @microgen middleware, logging
+//	// @http method=GET path=/users/{id} tags=[users, "public api"]
+//
+// Annotation is a comment line, which starts with prefix and name. Name is followed by positional values
+// and `key=value` parameters, separated by spaces or commas. Values may be quoted with double quotes or back quotes,
+// parameter value may be a list in square brackets.
+type AnnotationParser struct {
+	Prefix string   // Prefix of annotations, DefaultAnnotationPrefix is used, when it is empty.
+	Names  []string // Names of known annotations. When it is not empty, other annotations are ignored.
+}
+
+// Annotate parses annotations from comments of all entities of the file, which are visited by types.WalkBases,
+// and stores them to Annotations of entities.
+// Malformed annotations are skipped and returned as scanner.ErrorList with positions, resolved by f.FileSet.
+func (p AnnotationParser) Annotate(f *types.File) error {
+	var errs scanner.ErrorList
+	types.WalkBases(f, func(b *types.Base) {
+		annotations, err := p.parseBase(*b)
+		b.Annotations = annotations
+		for _, e := range err {
+			errs.Add(f.Position(e.pos), e.err.Error())
+		}
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type annotationError struct {
+	pos token.Pos
+	err error
+}
+
+// Parses annotations from comments of entity.
+// When entity has no comments model, e.g. it was constructed manually, Docs are used.
+func (p AnnotationParser) parseBase(b types.Base) ([]types.Annotation, []annotationError) {
+	var (
+		annotations []types.Annotation
+		errs        []annotationError
+	)
+	parse := func(raw string, pos token.Pos) {
+		for _, line := range commentLines(raw, pos) {
+			a, ok, err := p.ParseLine(line.text)
+			if err != nil {
+				errs = append(errs, annotationError{pos: line.pos, err: err})
+				continue
+			}
+			if ok {
+				a.Pos = line.pos
+				annotations = append(annotations, a)
+			}
+		}
+	}
+	if b.Comments == nil {
+		for _, doc := range b.Docs {
+			parse(doc, token.NoPos)
+		}
+		return annotations, errs
+	}
+	for _, c := range b.Comments.List() {
+		for i := range c.Raw {
+			pos := token.NoPos
+			if i < len(c.Positions) {
+				pos = c.Positions[i]
+			}
+			parse(c.Raw[i], pos)
+		}
+	}
+	return annotations, errs
+}
+
+type commentLine struct {
+	text string
+	pos  token.Pos
+}
+
+// Splits comment to lines without comment markers and leading spaces.
+// Position of each line points to its first not space character.
+func commentLines(raw string, pos token.Pos) []commentLine {
+	var (
+		text  string
+		lines []commentLine
+	)
+	switch {
+	case strings.HasPrefix(raw, "//"):
+		text = raw[2:]
+	case strings.HasPrefix(raw, "/*"):
+		text = strings.TrimSuffix(raw[2:], "*/")
+	default:
+		return nil
+	}
+	offset := 2
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+		linePos := token.NoPos
+		if pos.IsValid() {
+			linePos = pos + token.Pos(offset+len(line)-len(trimmed))
+		}
+		lines = append(lines, commentLine{text: trimmed, pos: linePos})
+		offset += len(line) + 1
+	}
+	return lines
+}
+
+// ParseLine parses annotation from one line of comment without comment markers.
+// It returns false, when line is not an annotation or annotation is not known.
+func (p AnnotationParser) ParseLine(line string) (types.Annotation, bool, error) {
+	prefix := p.Prefix
+	if prefix == "" {
+		prefix = DefaultAnnotationPrefix
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, prefix) {
+		return types.Annotation{}, false, nil
+	}
+	rest := line[len(prefix):]
+	if rest == "" || !unicode.IsLetter([]rune(rest)[0]) {
+		return types.Annotation{}, false, nil
+	}
+	end := strings.IndexFunc(rest, unicode.IsSpace)
+	if end < 0 {
+		end = len(rest)
+	}
+	a := types.Annotation{Name: rest[:end], Raw: line}
+	if !p.isKnown(a.Name) {
+		return types.Annotation{}, false, nil
+	}
+	for _, r := range a.Name {
+		if !isAnnotationNameRune(r) {
+			return types.Annotation{}, false, fmt.Errorf("malformed annotation %s%s: unexpected %q in name", prefix, a.Name, r)
+		}
+	}
+	var err error
+	a.Values, a.Params, err = parseAnnotationArgs(rest[end:])
+	if err != nil {
+		return types.Annotation{}, false, fmt.Errorf("malformed annotation %s%s: %v", prefix, a.Name, err)
+	}
+	return a, true, nil
+}
+
+func (p AnnotationParser) isKnown(name string) bool {
+	if len(p.Names) == 0 {
+		return true
+	}
+	for _, n := range p.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isAnnotationNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:", r)
+}
+
+func isAnnotationSeparator(b byte) bool {
+	return b == ' ' || b == '\t' || b == ','
+}
+
+// Parses positional values and `key=value` parameters of annotation.
+func parseAnnotationArgs(s string) (values []string, params []types.AnnotationParam, err error) {
+	for {
+		for len(s) > 0 && isAnnotationSeparator(s[0]) {
+			s = s[1:]
+		}
+		if s == "" {
+			return values, params, nil
+		}
+		if s[0] == '"' || s[0] == '`' {
+			var value string
+			value, s, err = cutAnnotationQuoted(s)
+			if err != nil {
+				return nil, nil, err
+			}
+			values = append(values, value)
+			continue
+		}
+		end := 0
+		for end < len(s) && !isAnnotationSeparator(s[end]) && s[end] != '=' {
+			end++
+		}
+		word := s[:end]
+		s = s[end:]
+		if s == "" || s[0] != '=' {
+			values = append(values, word)
+			continue
+		}
+		if word == "" {
+			return nil, nil, errors.New("parameter without key")
+		}
+		for i := range params {
+			if params[i].Key == word {
+				return nil, nil, fmt.Errorf("duplicate parameter %s", word)
+			}
+		}
+		param := types.AnnotationParam{Key: word}
+		param.Values, s, err = parseAnnotationParamValue(s[1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("parameter %s: %v", word, err)
+		}
+		params = append(params, param)
+	}
+}
+
+// Parses value of parameter after `=`: quoted string, list in square brackets or bare word.
+func parseAnnotationParamValue(s string) ([]string, string, error) {
+	if s == "" || isAnnotationSeparator(s[0]) {
+		return nil, s, errors.New("empty value")
+	}
+	switch s[0] {
+	case '"', '`':
+		value, rest, err := cutAnnotationQuoted(s)
+		if err != nil {
+			return nil, s, err
+		}
+		return []string{value}, rest, nil
+	case '[':
+		var list []string
+		s = s[1:]
+		for {
+			for len(s) > 0 && isAnnotationSeparator(s[0]) {
+				s = s[1:]
+			}
+			if s == "" {
+				return nil, s, errUnterminatedList
+			}
+			if s[0] == ']' {
+				return list, s[1:], nil
+			}
+			if s[0] == '"' || s[0] == '`' {
+				value, rest, err := cutAnnotationQuoted(s)
+				if err != nil {
+					return nil, s, err
+				}
+				list, s = append(list, value), rest
+				continue
+			}
+			end := 0
+			for end < len(s) && !isAnnotationSeparator(s[end]) && s[end] != ']' {
+				end++
+			}
+			list, s = append(list, s[:end]), s[end:]
+		}
+	default:
+		end := 0
+		for end < len(s) && !isAnnotationSeparator(s[end]) {
+			end++
+		}
+		return []string{s[:end]}, s[end:], nil
+	}
+}
+
+func cutAnnotationQuoted(s string) (value, rest string, err error) {
+	value, rest, ok := cutQuoted(s)
+	if !ok {
+		return "", s, errUnterminatedQuote
+	}
+	return value, rest, nil
+}
@@ -0,0 +1,345 @@
+package astra
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	astparser "go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Format of cached entries, it is changed, when entries of previous format can not be decoded.
+const cacheFormat = 1
+
+var (
+	cacheDir   string
+	cacheDirMx sync.RWMutex
+
+	cacheVersionOnce sync.Once
+	cacheVersionStr  string
+)
+
+func init() {
+	// Concrete types, which are stored in types.Type fields.
+	for _, t := range []types.Type{
+		types.TInterface{}, types.TMap{}, types.TName{}, types.TPointer{}, types.TArray{}, types.TImport{},
+		types.TEllipsis{}, types.TChan{}, types.TUnion{}, types.Struct{}, &types.Function{},
+	} {
+		gob.Register(t)
+	}
+}
+
+// SetCacheDir enables persistent cache of parsed files in dir, which is created, when it does not exist.
+// ParseFile, ParseSource, ParseReader and GetPackage results are stored by hash of sources, options, names of imported
+// packages and astra version, so entries are invalidated automatically, when anything of it changes.
+// Names of packages from lookups of imports are stored too, they are invalidated by sizes and modification times of files.
+// Files with `//line` directives and loads with CheckTypes option are not cached, because they depend on other files.
+// Cache is shared by the whole process and may be shared by processes, empty dir disables it.
+func SetCacheDir(dir string) error {
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("can not filepath.Abs: %v", err)
+		}
+		for _, sub := range []string{"files", "names"} {
+			if err := os.MkdirAll(filepath.Join(abs, sub), 0755); err != nil {
+				return fmt.Errorf("can not create cache dir: %v", err)
+			}
+		}
+		dir = abs
+	}
+	cacheDirMx.Lock()
+	cacheDir = dir
+	cacheDirMx.Unlock()
+	return nil
+}
+
+func currentCacheDir() string {
+	cacheDirMx.RLock()
+	defer cacheDirMx.RUnlock()
+	return cacheDir
+}
+
+// Returns version of astra, which is a part of every cache key. Development builds do not have version of module,
+// so executable is used instead of it.
+func cacheVersion() string {
+	cacheVersionOnce.Do(func() {
+		const astraModule = "github.com/vetcher/go-astra"
+		version := ""
+		if info, ok := debug.ReadBuildInfo(); ok {
+			modules := append([]*debug.Module{&info.Main}, info.Deps...)
+			for _, mod := range modules {
+				if mod.Path != astraModule {
+					continue
+				}
+				version = mod.Version
+				if mod.Replace != nil {
+					version = mod.Replace.Version
+				}
+			}
+		}
+		if version == "" || version == "(devel)" {
+			version = "devel"
+			if exe, err := os.Executable(); err == nil {
+				if info, err := os.Stat(exe); err == nil {
+					version += fmt.Sprintf(" %s %d %d", exe, info.Size(), info.ModTime().UnixNano())
+				}
+			}
+		}
+		cacheVersionStr = strings.Join([]string{
+			strconv.Itoa(cacheFormat), version, runtime.Version(),
+			build.Default.GOOS, build.Default.GOARCH, strings.Join(build.Default.BuildTags, ","),
+		}, "\n")
+	})
+	return cacheVersionStr
+}
+
+// Returns hex of sha256 of all parts.
+func cacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Returns key of parsed sources or false, when they should not be cached.
+// Key contains names of imported packages, which are found by r, because they are resolved from other directories.
+func sourcesCacheKey(opt Option, r *Resolver, names []string, sources [][]byte) (string, bool) {
+	if opt.check(CheckTypes) {
+		return "", false
+	}
+	parts := []string{cacheVersion(), strconv.FormatUint(uint64(opt), 10)}
+	fset := token.NewFileSet()
+	for i, src := range sources {
+		if bytes.Contains(src, []byte("//line ")) || bytes.Contains(src, []byte("/*line ")) {
+			return "", false
+		}
+		sum := sha256.Sum256(src)
+		parts = append(parts, names[i], hex.EncodeToString(sum[:]))
+		file, err := astparser.ParseFile(fset, names[i], src, astparser.ImportsOnly)
+		if err != nil {
+			return "", false
+		}
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return "", false
+			}
+			name, guessed := packageName(r, importPath)
+			parts = append(parts, importPath, name, strconv.FormatBool(guessed))
+		}
+	}
+	return cacheKey(parts...), true
+}
+
+// cachedFile is types.File without FileSet, which is stored in cache. Fields should be in sync with types.File.
+type cachedFile struct {
+	types.Base
+	Imports          []*types.Import
+	Constants        []types.Variable
+	Vars             []types.Variable
+	Interfaces       []types.Interface
+	Structures       []types.Struct
+	Functions        []types.Function
+	Methods          []types.Method
+	Types            []types.FileType
+	FloatingComments []*types.Comment
+	License          *types.Comment
+	IsGenerated      bool
+	Diagnostics      []types.Diagnostic
+
+	TokenFiles []cachedTokenFile // Files of FileSet, so positions of entities stay valid.
+}
+
+type cachedTokenFile struct {
+	Name  string
+	Base  int
+	Size  int
+	Lines []int
+}
+
+// Returns file from cache or nil, when it is not found or can not be decoded.
+func loadCachedFile(key string) *types.File {
+	dir := currentCacheDir()
+	if dir == "" {
+		return nil
+	}
+	name := filepath.Join(dir, "files", key)
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	var c cachedFile
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&c); err != nil {
+		os.Remove(name)
+		return nil
+	}
+	f := &types.File{
+		Base:             c.Base,
+		Imports:          c.Imports,
+		Constants:        c.Constants,
+		Vars:             c.Vars,
+		Interfaces:       c.Interfaces,
+		Structures:       c.Structures,
+		Functions:        c.Functions,
+		Methods:          c.Methods,
+		Types:            c.Types,
+		FloatingComments: c.FloatingComments,
+		License:          c.License,
+		IsGenerated:      c.IsGenerated,
+		Diagnostics:      c.Diagnostics,
+		FileSet:          token.NewFileSet(),
+	}
+	for _, tf := range c.TokenFiles {
+		if tf.Base < f.FileSet.Base() || !f.FileSet.AddFile(tf.Name, tf.Base, tf.Size).SetLines(tf.Lines) {
+			os.Remove(name)
+			return nil
+		}
+	}
+	if err := relinkCachedFile(f); err != nil {
+		os.Remove(name)
+		return nil
+	}
+	return f
+}
+
+// Restores pointers, which are shared between entities of parsed file: imports of types and methods of types.
+func relinkCachedFile(f *types.File) error {
+	f.MapTypes(func(t types.Type) types.Type {
+		if x, ok := t.(types.TImport); ok && x.Import != nil {
+			for _, imp := range f.Imports {
+				if imp != nil && imp.Package == x.Import.Package && imp.Name == x.Import.Name {
+					x.Import = imp
+					break
+				}
+			}
+			return x
+		}
+		return t
+	})
+	for i := range f.Structures {
+		f.Structures[i].Methods = nil
+	}
+	for i := range f.Types {
+		f.Types[i].Methods = nil
+	}
+	return linkMethodsToStructs(f)
+}
+
+// Stores file in cache. Errors are ignored, because cache is an optimization.
+func storeCachedFile(key string, f *types.File) {
+	dir := currentCacheDir()
+	if dir == "" {
+		return
+	}
+	c := cachedFile{
+		Base:             f.Base,
+		Imports:          f.Imports,
+		Constants:        f.Constants,
+		Vars:             f.Vars,
+		Interfaces:       f.Interfaces,
+		Structures:       f.Structures,
+		Functions:        f.Functions,
+		Methods:          f.Methods,
+		Types:            f.Types,
+		FloatingComments: f.FloatingComments,
+		License:          f.License,
+		IsGenerated:      f.IsGenerated,
+		Diagnostics:      f.Diagnostics,
+	}
+	if f.FileSet != nil {
+		f.FileSet.Iterate(func(tf *token.File) bool {
+			c.TokenFiles = append(c.TokenFiles, cachedTokenFile{Name: tf.Name(), Base: tf.Base(), Size: tf.Size(), Lines: tf.Lines()})
+			return true
+		})
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&c); err != nil {
+		return
+	}
+	writeCacheEntry(filepath.Join(dir, "files", key), buf.Bytes())
+}
+
+// Writes entry through temporary file, so concurrent readers never see partially written entries.
+func writeCacheEntry(name string, data []byte) {
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Returns key of package name lookup in dir or false, when dir contains overlay files, which have no modification times.
+func packageNameCacheKey(ov overlay, dir string, infos []os.FileInfo) (string, bool) {
+	if len(ov.dir(dir)) > 0 {
+		return "", false
+	}
+	parts := []string{cacheVersion(), dir}
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") {
+			parts = append(parts, info.Name(), strconv.FormatInt(info.Size(), 10), strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		}
+	}
+	return cacheKey(parts...), true
+}
+
+func loadCachedPackageName(key string) (string, bool) {
+	dir := currentCacheDir()
+	if dir == "" {
+		return "", false
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "names", key))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func storeCachedPackageName(key, name string) {
+	if dir := currentCacheDir(); dir != "" {
+		writeCacheEntry(filepath.Join(dir, "names", key), []byte(name))
+	}
+}
+
+// Parses sources with cache: cached file is returned, when key of sources is found, otherwise parse is called
+// and its result is stored.
+func parseCached(opt Option, r *Resolver, names []string, sources [][]byte, parse func() (*types.File, error)) (*types.File, error) {
+	if currentCacheDir() == "" {
+		return parse()
+	}
+	key, ok := sourcesCacheKey(opt, r, names, sources)
+	if !ok {
+		return parse()
+	}
+	if f := loadCachedFile(key); f != nil {
+		return f, nil
+	}
+	f, err := parse()
+	if err == nil {
+		storeCachedFile(key, f)
+	}
+	return f, err
+}
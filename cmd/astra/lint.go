@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+// Returned, when issues are printed, so command exits with non-zero code without message.
+var errIssuesFound = errors.New("issues found")
+
+func lintCommand(args []string) error {
+	var (
+		flags  = flag.NewFlagSet("lint", flag.ExitOnError)
+		keys   = flags.String("keys", "json,yaml,db", "comma-separated keys of tags, which names are checked")
+		format = flags.String("format", "text", "output format: text or json")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: astra lint [flags] path...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	issues := []astra.TagIssue{}
+	for _, path := range flags.Args() {
+		file, dir, err := loadPath(path)
+		if err != nil {
+			return err
+		}
+		resolver, err := astra.NewResolver(dir)
+		if err != nil {
+			return err
+		}
+		linter := astra.TagLinter{Keys: splitList(*keys), Resolver: resolver}
+		issues = append(issues, linter.Lint(file)...)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+	if len(issues) > 0 {
+		return errIssuesFound
+	}
+	return nil
+}
+
+// Parses file or package from directory. Returns directory of sources too.
+// Test files of package are not loaded, because external test package can not be merged with it.
+func loadPath(path string) (*types.File, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() {
+		dir, err := filepath.Abs(path)
+		if err != nil {
+			return nil, "", err
+		}
+		packages, err := astra.LoadPackages([]string{dir})
+		if err != nil {
+			return nil, "", err
+		}
+		return packages[0].File, path, nil
+	}
+	file, err := astra.ParseFile(path)
+	return file, filepath.Dir(path), err
+}
@@ -0,0 +1,48 @@
+// Command astra is a command line interface to go-astra.
+//
+// Usage:
+//
+//	astra tags [flags] path...
+//	astra lint [flags] path...
+//
+// Path is a file or a directory with package.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage:
+	astra tags [flags] path...	add, remove or rewrite struct tags
+	astra lint [flags] path...	check struct tags
+
+Run 'astra <command> -h' for help of command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "tags":
+		err = tagsCommand(os.Args[2:])
+	case "lint":
+		err = lintCommand(os.Args[2:])
+	case "-h", "-help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err == errIssuesFound {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
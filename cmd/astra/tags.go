@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/scanner"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vetcher/go-astra"
+)
+
+// Collects repeated `key=option,option` flags.
+type optionsFlag map[string][]string
+
+func (f optionsFlag) String() string {
+	var pairs []string
+	for key, options := range f {
+		pairs = append(pairs, key+"="+strings.Join(options, ","))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (f optionsFlag) Set(value string) error {
+	key, options, ok := strings.Cut(value, "=")
+	if !ok || key == "" || options == "" {
+		return errors.New("expected key=option[,option]")
+	}
+	f[key] = append(f[key], strings.Split(options, ",")...)
+	return nil
+}
+
+var namings = map[string]func(string) string{
+	"snake":  astra.SnakeCase,
+	"camel":  astra.CamelCase,
+	"kebab":  astra.KebabCase,
+	"pascal": astra.PascalCase,
+}
+
+func tagsCommand(args []string) error {
+	var (
+		flags         = flag.NewFlagSet("tags", flag.ExitOnError)
+		add           = flags.String("add", "", "comma-separated keys of tags to add to exported fields")
+		remove        = flags.String("remove", "", "comma-separated keys of tags to remove")
+		naming        = flags.String("case", "snake", "case of names in added tags: snake, camel, kebab or pascal")
+		overwrite     = flags.Bool("overwrite", false, "rewrite names of existing tags with keys from -add")
+		write         = flags.Bool("w", false, "write result to source files instead of stdout")
+		addOptions    = optionsFlag{}
+		removeOptions = optionsFlag{}
+	)
+	flags.Var(addOptions, "option", "add options to tags, e.g. json=omitempty, may be repeated")
+	flags.Var(removeOptions, "remove-option", "remove options from tags, e.g. json=omitempty, may be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: astra tags [flags] path...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	rewriter := astra.TagRewriter{
+		Add:           splitList(*add),
+		Remove:        splitList(*remove),
+		Naming:        namings[*naming],
+		Overwrite:     *overwrite,
+		AddOptions:    addOptions,
+		RemoveOptions: removeOptions,
+	}
+	if rewriter.Naming == nil {
+		return fmt.Errorf("unknown case %q", *naming)
+	}
+	files, err := goFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	var failed bool
+	for _, filename := range files {
+		result, err := rewriter.RewriteFile(filename)
+		if list, ok := err.(scanner.ErrorList); ok {
+			// Fields with malformed tags are skipped, other fields are rewritten.
+			scanner.PrintError(os.Stderr, list)
+			failed = true
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		if !*write {
+			os.Stdout.Write(result)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filename, result, info.Mode()); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return errors.New("some tags are not changed")
+	}
+	return nil
+}
+
+// Returns files from paths, directories are replaced with their .go files.
+func goFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
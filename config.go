@@ -0,0 +1,86 @@
+package astra
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Config is a configuration of loads, like Config of golang.org/x/tools/go/packages.
+// Package-level functions work like zero Config with options.
+type Config struct {
+	// Options are used for parsing of every file and package of the load.
+	Options []Option
+
+	// Overlay replaces contents of files on disk with files from the map: file path to its contents.
+	// Relative paths are relative to the working directory. Files from overlay may not exist on disk,
+	// they are visible for loaders, Resolver, lookup of names of imported packages and go.mod files search,
+	// e.g. unsaved buffers of editor may be parsed without writing them to temporary files.
+	// Overlay is used only by loads of this Config: names of packages, which are found with it, are not shared with other loads.
+	Overlay map[string][]byte
+}
+
+// ParseFile works like ParseFile, but contents of the file are taken from overlay, when it has the file.
+func (c Config) ParseFile(filename string) (*types.File, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	return parseFile(ov, filename, c.Options...)
+}
+
+// ParseSource works like ParseSource, imports are resolved with overlay.
+func (c Config) ParseSource(name string, src []byte) (*types.File, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	return parseSourceCached(ov, name, src, c.Options...)
+}
+
+// ParseReader reads all sources from r and parses them, see Config.ParseSource.
+func (c Config) ParseReader(name string, r io.Reader) (*types.File, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("can not read source: %v", err)
+	}
+	return c.ParseSource(name, src)
+}
+
+// GetPackage works like GetPackage, overlay files are parsed too.
+func (c Config) GetPackage(path string) (*types.File, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	return getPackage(ov, path, c.Options...)
+}
+
+// LoadPackages works like LoadPackages, directories of overlay files are matched too.
+func (c Config) LoadPackages(patterns []string) ([]Package, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	return loadPackages(ov, patterns, c.Options...)
+}
+
+// NewResolver returns Resolver for imports of the package in dir, which reads files from overlay, see NewResolver.
+func (c Config) NewResolver(dir string) (*Resolver, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	return newResolver(dir, ov, c.Options...)
+}
+
+// ImportPath works like ImportPath, go.mod files are searched in overlay too.
+func (c Config) ImportPath(dir string) (string, error) {
+	ov, err := newOverlay(c.Overlay)
+	if err != nil {
+		return "", err
+	}
+	return importPath(ov, dir)
+}
@@ -0,0 +1,148 @@
+package astra
+
+import (
+	"go/ast"
+	"strconv"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Collects directives from comment groups.
+func parseDirectives(groups ...*ast.CommentGroup) []types.Directive {
+	var directives []types.Directive
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			if d, ok := parseDirective(comment); ok {
+				directives = append(directives, d)
+			}
+		}
+	}
+	return directives
+}
+
+// Collects directives of spec of general declaration. Directives above not grouped declaration belong to its spec,
+// directives above declarations group belong to the file.
+func parseSpecDirectives(decl *ast.GenDecl, doc, trailing *ast.CommentGroup) []types.Directive {
+	if !decl.Lparen.IsValid() {
+		return parseDirectives(decl.Doc, doc, trailing)
+	}
+	return parseDirectives(doc, trailing)
+}
+
+// Collects directives from comments, that are not attached to any declaration, spec or field:
+// file documentation, build constraints, comments inside function bodies and documentation of declarations groups.
+func parseFileDirectives(file *ast.File) []types.Directive {
+	attached := attachedComments(file)
+	var groups []*ast.CommentGroup
+	for _, group := range file.Comments {
+		if !attached[group] {
+			groups = append(groups, group)
+		}
+	}
+	return parseDirectives(groups...)
+}
+
+// Parses one comment as directive. Comment is a directive, when it starts with `//` without space
+// and is followed by `name:name`, `nolint`, `line `, `export ` or `extern `, or it is a `// +build` constraint.
+func parseDirective(comment *ast.Comment) (types.Directive, bool) {
+	d := types.Directive{Raw: comment.Text, Pos: comment.Slash}
+	text := comment.Text
+	if !strings.HasPrefix(text, "//") {
+		return d, false
+	}
+	text = text[2:]
+	if strings.HasPrefix(text, " +build ") {
+		d.Name = "+build"
+		d.Args = strings.Fields(text[len(" +build "):])
+		return d, true
+	}
+	if text == "" || text[0] == ' ' || text[0] == '\t' {
+		return d, false
+	}
+	name, rest := text, ""
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		name, rest = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	switch {
+	case name == types.DirectiveNolint || strings.HasPrefix(name, types.DirectiveNolint+":"):
+		d.Name = types.DirectiveNolint
+		if linters := strings.TrimPrefix(name, types.DirectiveNolint); linters != "" {
+			d.Args = strings.Split(linters[1:], ",")
+		}
+		return d, true
+	case name == "line" || name == "export" || name == "extern":
+		d.Name = name
+		d.Args = strings.Fields(rest)
+		return d, true
+	case isDirectiveName(name):
+		d.Name = name
+		d.Args = splitDirectiveArgs(rest)
+		return d, true
+	}
+	return d, false
+}
+
+// Checks that name matches `[a-z0-9]+:[a-z0-9]`, the same rule is used by go/ast.
+func isDirectiveName(name string) bool {
+	colon := strings.Index(name, ":")
+	if colon <= 0 || colon+1 >= len(name) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := name[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Splits arguments by spaces. Double-quoted and back-quoted arguments are unquoted,
+// as `go generate` and `go:embed` do it.
+func splitDirectiveArgs(s string) []string {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return args
+		}
+		end := strings.IndexAny(s, " \t")
+		if s[0] == '"' || s[0] == '`' {
+			if arg, rest, ok := cutQuoted(s); ok {
+				args = append(args, arg)
+				s = rest
+				continue
+			}
+		}
+		if end < 0 {
+			return append(args, s)
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+}
+
+func cutQuoted(s string) (arg, rest string, ok bool) {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			arg, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			return arg, s[i+1:], true
+		}
+	}
+	return "", "", false
+}
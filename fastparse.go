@@ -0,0 +1,162 @@
+package astra
+
+import (
+	"bytes"
+	"go/ast"
+	astparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"runtime"
+	"sync"
+)
+
+// Number of files, which are parsed concurrently by loaders.
+var parseConcurrency = runtime.GOMAXPROCS(0)
+
+// Parses source file without bodies of top-level functions and methods, because astra never inspects them,
+// unless bodies are requested with KeepFuncBodies or CheckTypes options.
+// Comments inside bodies are kept, so directives and positions of all entities are the same, as after full parsing.
+func parseGoFile(fset *token.FileSet, filename string, src []byte, mode astparser.Mode, opt Option) (*ast.File, error) {
+	if mode&(astparser.PackageClauseOnly|astparser.ImportsOnly) == 0 && !opt.check(KeepFuncBodies) && !opt.check(CheckTypes) {
+		src = stripFuncBodies(src)
+	}
+	return astparser.ParseFile(fset, filename, src, mode)
+}
+
+// Returns copy of src, where statements of top-level function bodies are replaced with spaces.
+// Line breaks and comments are not changed, so offsets, lines and columns of the rest of the file are kept.
+// Source with syntax errors is returned as is, so parser reports them.
+func stripFuncBodies(src []byte) []byte {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(src))
+	var (
+		s         scanner.Scanner
+		errs      int
+		stripped  []byte
+		depth     int         // nesting of parentheses, brackets and braces
+		prev      token.Token // previous not comment token
+		inFunc    bool        // top-level function declaration, which body is not found yet
+		bodyStart = -1        // offset right after `{` of the current body
+		comments  [][2]int    // offsets of comments inside the current body
+	)
+	s.Init(file, src, func(token.Position, string) { errs++ }, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		offset := file.Offset(pos)
+		switch tok {
+		case token.COMMENT:
+			if bodyStart >= 0 {
+				comments = append(comments, [2]int{offset, offset + commentLen(src[offset:], lit)})
+			}
+			continue
+		case token.FUNC:
+			// declarations start after semicolon, function literals of variables are left as is
+			if depth == 0 && (prev == token.SEMICOLON || prev == token.ILLEGAL) {
+				inFunc = true
+			}
+		case token.LPAREN, token.LBRACK:
+			depth++
+		case token.RPAREN, token.RBRACK:
+			depth--
+		case token.LBRACE:
+			if inFunc && depth == 0 && prev != token.STRUCT && prev != token.INTERFACE {
+				inFunc = false
+				bodyStart = offset + 1
+				comments = comments[:0]
+			}
+			depth++
+		case token.RBRACE:
+			depth--
+			if depth == 0 && bodyStart >= 0 {
+				if stripped == nil {
+					stripped = append([]byte(nil), src...)
+				}
+				blank(stripped, bodyStart, offset, comments)
+				bodyStart = -1
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				inFunc = false // function without body
+			}
+		}
+		prev = tok
+	}
+	if errs > 0 || stripped == nil {
+		return src
+	}
+	return stripped
+}
+
+// Returns length of comment in the source. Scanner removes carriage returns from text of comments,
+// so the end is searched in the source.
+func commentLen(src []byte, text string) int {
+	if len(text) > 1 && text[1] == '/' {
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			return i
+		}
+		return len(src)
+	}
+	if i := bytes.Index(src[2:], []byte("*/")); i >= 0 {
+		return i + 4
+	}
+	return len(src)
+}
+
+// Replaces bytes of src[start:end] with spaces, except line breaks and comments.
+func blank(src []byte, start, end int, comments [][2]int) {
+	for _, c := range comments {
+		blankRange(src, start, c[0])
+		start = c[1]
+	}
+	blankRange(src, start, end)
+}
+
+func blankRange(src []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if src[i] != '\n' {
+			src[i] = ' '
+		}
+	}
+}
+
+type parsedFile struct {
+	file *ast.File
+	err  error
+}
+
+// Parses files concurrently. Results are returned in order of filenames.
+func parseGoFiles(fset *token.FileSet, filenames []string, read func(string) ([]byte, error), mode astparser.Mode, opt Option) ([]*ast.File, error) {
+	results := make([]parsedFile, len(filenames))
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parseConcurrency)
+	)
+	for i := range filenames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			src, err := read(filenames[i])
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].file, results[i].err = parseGoFile(fset, filenames[i], src, mode, opt)
+		}(i)
+	}
+	wg.Wait()
+	files := make([]*ast.File, len(filenames))
+	for i := range results {
+		if results[i].err != nil {
+			return nil, results[i].err
+		}
+		files[i] = results[i].file
+	}
+	return files, nil
+}
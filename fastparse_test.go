@@ -0,0 +1,166 @@
+package astra
+
+import (
+	"fmt"
+	astparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripFuncBodies(t *testing.T) {
+	src := "package p\r\n" +
+		"\n" +
+		"var f = func() int { return 1 }\n" +
+		"\n" +
+		"func A() interface{ M() } {\n" +
+		"\t// directive inside body\n" +
+		"\ts := `raw\n/* not a comment */`\n" +
+		"\t/* block\r\n\t   comment */\n" +
+		"\treturn nil // trailing\n" +
+		"}\n" +
+		"\n" +
+		"func B[T interface{ ~int }](x struct{ a T }) struct{} { if x.a > 0 { return struct{}{} }; return struct{}{} }\n" +
+		"\n" +
+		"func asm(x int) int\n" +
+		"\n" +
+		"func (r *R) C() {}\n"
+	actual := string(stripFuncBodies([]byte(src)))
+	if len(actual) != len(src) || strings.Count(actual, "\n") != strings.Count(src, "\n") {
+		t.Fatalf("offsets and lines of source should be kept, found:\n%q", actual)
+	}
+	for _, kept := range []string{
+		"var f = func() int { return 1 }\n",
+		"func A() interface{ M() } {\n",
+		" // directive inside body\n",
+		"/* block\r\n\t   comment */\n",
+		" // trailing\n}\n",
+		"func B[T interface{ ~int }](x struct{ a T }) struct{} {",
+		"func asm(x int) int\n",
+		"func (r *R) C() {}\n",
+	} {
+		if !strings.Contains(actual, kept) {
+			t.Errorf("%q should be kept, found:\n%q", kept, actual)
+		}
+	}
+	for _, stripped := range []string{"`raw", "not a comment", "return nil", "x.a > 0"} {
+		if strings.Contains(actual, stripped) {
+			t.Errorf("%q should be stripped, found:\n%q", stripped, actual)
+		}
+	}
+	if _, err := astparser.ParseFile(token.NewFileSet(), "", actual, astparser.ParseComments); err != nil {
+		t.Fatal(err)
+	}
+	broken := "package p\n\nfunc A() { \"unterminated }\n"
+	if actual := string(stripFuncBodies([]byte(broken))); actual != broken {
+		t.Errorf("source with errors should not be changed, found %q", actual)
+	}
+}
+
+func TestKeepFuncBodies(t *testing.T) {
+	src := []byte("package p\n\nfunc A() int { x := ; return }\n")
+	if _, err := ParseSource("a.go", src); err != nil {
+		t.Errorf("bodies should be skipped by default, found %v", err)
+	}
+	for _, opt := range []Option{KeepFuncBodies, CheckTypes} {
+		if _, err := ParseSource("a.go", src, opt); err == nil {
+			t.Errorf("%d: syntax error in body should be reported", opt)
+		}
+	}
+	if _, err := ParseSource("b.go", []byte("package p\n\nfunc A() { \"unterminated }\n")); err == nil {
+		t.Error("lexical error in body should be reported by default")
+	}
+}
+
+// Writes synthetic package with files of structures, interfaces and functions with large bodies.
+func writeSyntheticPackage(b *testing.B, files int) string {
+	dir := b.TempDir()
+	for i := 0; i < files; i++ {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package synthetic\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\n")
+		for j := 0; j < 20; j++ {
+			fmt.Fprintf(&src, "// S%[1]d_%[2]d is a structure.\ntype S%[1]d_%[2]d struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n\tNext *S%[1]d_%[2]d\n}\n\n", i, j)
+			fmt.Fprintf(&src, "type I%[1]d_%[2]d interface {\n\tGet(id int) (*S%[1]d_%[2]d, error)\n}\n\n", i, j)
+			fmt.Fprintf(&src, "func (s *S%[1]d_%[2]d) String() string {\n", i, j)
+			for k := 0; k < 30; k++ {
+				fmt.Fprintf(&src, "\tif s.ID == %[1]d {\n\t\treturn strings.Repeat(fmt.Sprintf(\"%%s-%%d\", s.Name, %[1]d), 2)\n\t}\n", k)
+			}
+			fmt.Fprintf(&src, "\treturn s.Name\n}\n\n")
+		}
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", i)), []byte(src.String()), 0644)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func BenchmarkParseFile(b *testing.B) {
+	dir := writeSyntheticPackage(b, 1)
+	filename := filepath.Join(dir, "file0.go")
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("FullBodies", func(b *testing.B) {
+		b.SetBytes(int64(len(src)))
+		for i := 0; i < b.N; i++ {
+			if _, err := astparser.ParseFile(token.NewFileSet(), filename, src, astparser.ParseComments); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("SkipBodies", func(b *testing.B) {
+		b.SetBytes(int64(len(src)))
+		for i := 0; i < b.N; i++ {
+			if _, err := parseGoFile(token.NewFileSet(), filename, src, astparser.ParseComments, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetPackage(b *testing.B) {
+	dir := writeSyntheticPackage(b, 50)
+	for _, bench := range []struct {
+		name        string
+		concurrency int
+		options     []Option
+	}{
+		{"FullBodies/Sequential", 1, []Option{KeepFuncBodies}},
+		{"FullBodies/Concurrent", parseConcurrency, []Option{KeepFuncBodies}},
+		{"SkipBodies/Sequential", 1, nil},
+		{"SkipBodies/Concurrent", parseConcurrency, nil},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			defer func(old int) { parseConcurrency = old }(parseConcurrency)
+			parseConcurrency = bench.concurrency
+			for i := 0; i < b.N; i++ {
+				if _, err := GetPackage(dir, bench.options...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPackageName(b *testing.B) {
+	dir := writeSyntheticPackage(b, 50)
+	b.Run("ParseDir", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pkgs, err := astparser.ParseDir(token.NewFileSet(), dir, nil, astparser.PackageClauseOnly)
+			if err != nil || len(pkgs) != 1 {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("PackageClause", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if name := findPackageName(nil, dir); name != "synthetic" {
+				b.Fatalf("unexpected name %s", name)
+			}
+		}
+	})
+}
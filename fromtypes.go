@@ -0,0 +1,278 @@
+package astra
+
+import (
+	"go/ast"
+	gotypes "go/types"
+	"strconv"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Converter converts objects of go/types package to astra types.
+// Types from other packages are converted to types.TImport, imports for them are synthesized
+// and shared between all converted types.
+type Converter struct {
+	pkg     *gotypes.Package
+	imports []*types.Import
+	byPath  map[string]*types.Import
+}
+
+// NewConverter returns Converter for types of pkg.
+// Types, declared in pkg, are converted to types.TName without import.
+func NewConverter(pkg *gotypes.Package) *Converter {
+	return &Converter{
+		pkg:    pkg,
+		byPath: make(map[string]*types.Import),
+	}
+}
+
+// Imports returns all imports, that were used by converted types, in order of their first usage.
+func (c *Converter) Imports() []*types.Import {
+	return c.imports
+}
+
+// ConvertPackage converts all package-level declarations of type-checked package to one file.
+func ConvertPackage(pkg *gotypes.Package) *types.File {
+	c := NewConverter(pkg)
+	f := &types.File{
+		Base: types.Base{
+			Name: pkg.Name(),
+		},
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *gotypes.Const:
+			f.Constants = append(f.Constants, c.Variable(obj))
+		case *gotypes.Var:
+			f.Vars = append(f.Vars, c.Variable(obj))
+		case *gotypes.Func:
+			f.Functions = append(f.Functions, c.Function(obj))
+		case *gotypes.TypeName:
+			c.addTypeName(f, obj)
+		}
+	}
+	f.Imports = c.Imports()
+	// methods are linked only with named types, so error is not possible.
+	_ = linkMethodsToStructs(f)
+	return f
+}
+
+func (c *Converter) addTypeName(f *types.File, obj *gotypes.TypeName) {
+	var typeParams []types.Variable
+	if named, ok := obj.Type().(*gotypes.Named); ok && !obj.IsAlias() {
+		typeParams = c.typeParams(named.TypeParams())
+		for i := 0; i < named.NumMethods(); i++ {
+			f.Methods = append(f.Methods, c.Method(named.Method(i)))
+		}
+	}
+	base := types.Base{Name: obj.Name()}
+	if obj.IsAlias() {
+		f.Types = append(f.Types, types.FileType{Base: base, Type: c.Type(gotypes.Unalias(obj.Type()))})
+		return
+	}
+	switch u := obj.Type().Underlying().(type) {
+	case *gotypes.Struct:
+		f.Structures = append(f.Structures, types.Struct{Base: base, TypeParams: typeParams, Fields: c.fields(u)})
+	case *gotypes.Interface:
+		iface := c.Interface(u)
+		iface.Base, iface.TypeParams = base, typeParams
+		f.Interfaces = append(f.Interfaces, iface)
+	default:
+		f.Types = append(f.Types, types.FileType{Base: base, TypeParams: typeParams, Type: c.Type(u)})
+	}
+}
+
+// Variable converts variable, constant or struct field.
+// Types of untyped constants are replaced with their default types.
+func (c *Converter) Variable(obj gotypes.Object) types.Variable {
+	return types.Variable{
+		Base: types.Base{Name: obj.Name()},
+		Type: c.Type(gotypes.Default(obj.Type())),
+	}
+}
+
+// Function converts function or method without receiver.
+func (c *Converter) Function(fn *gotypes.Func) types.Function {
+	f := c.signature(fn.Type().(*gotypes.Signature))
+	f.Name = fn.Name()
+	return *f
+}
+
+// Method converts method with its receiver.
+func (c *Converter) Method(fn *gotypes.Func) types.Method {
+	sig := fn.Type().(*gotypes.Signature)
+	m := types.Method{Function: c.Function(fn)}
+	if recv := sig.Recv(); recv != nil {
+		m.Receiver = c.Variable(recv)
+	}
+	return m
+}
+
+// Interface converts interface type with its explicit methods and embedded types.
+func (c *Converter) Interface(iface *gotypes.Interface) types.Interface {
+	var result types.Interface
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		fn := c.Function(iface.ExplicitMethod(i))
+		result.Methods = append(result.Methods, &fn)
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		result.Interfaces = append(result.Interfaces, types.Variable{Type: c.Type(iface.EmbeddedType(i))})
+	}
+	return result
+}
+
+// Type converts go/types type to astra type.
+func (c *Converter) Type(t gotypes.Type) types.Type {
+	switch x := t.(type) {
+	case *gotypes.Basic:
+		if x.Kind() == gotypes.UnsafePointer {
+			return types.TImport{Import: c.importOf(gotypes.Unsafe), Next: types.TName{TypeName: "Pointer"}}
+		}
+		return types.TName{TypeName: x.Name()}
+	case *gotypes.Named:
+		return c.named(x.Obj(), x.TypeArgs())
+	case *gotypes.Alias:
+		return c.named(x.Obj(), nil)
+	case *gotypes.TypeParam:
+		return types.TName{TypeName: x.Obj().Name()}
+	case *gotypes.Pointer:
+		next := c.Type(x.Elem())
+		if p, ok := next.(types.TPointer); ok {
+			return types.TPointer{Next: p.Next, NumberOfPointers: p.NumberOfPointers + 1}
+		}
+		return types.TPointer{Next: next, NumberOfPointers: 1}
+	case *gotypes.Slice:
+		return types.TArray{Next: c.Type(x.Elem()), IsSlice: true}
+	case *gotypes.Array:
+		return types.TArray{Next: c.Type(x.Elem()), ArrayLen: int(x.Len())}
+	case *gotypes.Map:
+		return types.TMap{Key: c.Type(x.Key()), Value: c.Type(x.Elem())}
+	case *gotypes.Chan:
+		return types.TChan{Next: c.Type(x.Elem()), Direction: chanDirections[x.Dir()]}
+	case *gotypes.Signature:
+		return c.signature(x)
+	case *gotypes.Struct:
+		return types.Struct{Fields: c.fields(x)}
+	case *gotypes.Interface:
+		iface := c.Interface(x)
+		return types.TInterface{Interface: &iface}
+	case *gotypes.Union:
+		terms := make([]types.TTerm, x.Len())
+		for i := range terms {
+			terms[i] = types.TTerm{Tilde: x.Term(i).Tilde(), Type: c.Type(x.Term(i).Type())}
+		}
+		return types.TUnion{Terms: terms}
+	default:
+		return nil
+	}
+}
+
+var chanDirections = map[gotypes.ChanDir]int{
+	gotypes.SendRecv: types.ChanDirAny,
+	gotypes.SendOnly: types.ChanDirSend,
+	gotypes.RecvOnly: types.ChanDirRecv,
+}
+
+func (c *Converter) named(obj *gotypes.TypeName, typeArgs *gotypes.TypeList) types.Type {
+	name := types.TName{TypeName: obj.Name()}
+	for i := 0; i < typeArgs.Len(); i++ {
+		name.TypeArgs = append(name.TypeArgs, c.Type(typeArgs.At(i)))
+	}
+	if obj.Pkg() == nil || obj.Pkg() == c.pkg {
+		return name
+	}
+	return types.TImport{Import: c.importOf(obj.Pkg()), Next: name}
+}
+
+func (c *Converter) importOf(pkg *gotypes.Package) *types.Import {
+	if imp, ok := c.byPath[pkg.Path()]; ok {
+		return imp
+	}
+	name := pkg.Name()
+	for n := 2; c.aliasUsed(name); n++ {
+		name = pkg.Name() + strconv.Itoa(n)
+	}
+	imp := &types.Import{
+		Base:     types.Base{Name: name},
+		Package:  pkg.Path(),
+		Kind:     types.ImportNormal,
+		RealName: pkg.Name(),
+	}
+	if name != pkg.Name() {
+		imp.Kind = types.ImportAliased
+	}
+	c.byPath[pkg.Path()] = imp
+	c.imports = append(c.imports, imp)
+	return imp
+}
+
+func (c *Converter) aliasUsed(name string) bool {
+	for _, imp := range c.imports {
+		if imp.Name == name {
+			return true
+		}
+	}
+	return types.IsBuiltinString(name) || (c.pkg != nil && c.pkg.Scope().Lookup(name) != nil)
+}
+
+func (c *Converter) signature(sig *gotypes.Signature) *types.Function {
+	fn := &types.Function{
+		TypeParams: c.typeParams(sig.TypeParams()),
+		Args:       c.tuple(sig.Params()),
+		Results:    c.tuple(sig.Results()),
+	}
+	if sig.Variadic() && len(fn.Args) > 0 {
+		last := &fn.Args[len(fn.Args)-1]
+		if slice, ok := last.Type.(types.TArray); ok {
+			last.Type = types.TEllipsis{Next: slice.Next}
+		}
+	}
+	return fn
+}
+
+func (c *Converter) tuple(tuple *gotypes.Tuple) []types.Variable {
+	var vars []types.Variable
+	for i := 0; i < tuple.Len(); i++ {
+		vars = append(vars, c.Variable(tuple.At(i)))
+	}
+	return vars
+}
+
+func (c *Converter) typeParams(list *gotypes.TypeParamList) []types.Variable {
+	var vars []types.Variable
+	for i := 0; i < list.Len(); i++ {
+		constraint := list.At(i).Constraint()
+		// `[T ~int]` is a shortcut for `[T interface{~int}]`.
+		if iface, ok := constraint.(*gotypes.Interface); ok && iface.IsImplicit() && iface.NumEmbeddeds() == 1 {
+			constraint = iface.EmbeddedType(0)
+		}
+		vars = append(vars, types.Variable{
+			Base: types.Base{Name: list.At(i).Obj().Name()},
+			Type: c.Type(constraint),
+		})
+	}
+	return vars
+}
+
+func (c *Converter) fields(s *gotypes.Struct) []types.StructField {
+	var fields []types.StructField
+	for i := 0; i < s.NumFields(); i++ {
+		v := c.Variable(s.Field(i))
+		if s.Field(i).Embedded() {
+			v.Name = ""
+		}
+		var lit *ast.BasicLit
+		if tag := s.Tag(i); tag != "" {
+			lit = &ast.BasicLit{Value: "`" + tag + "`"}
+		}
+		parsedTags, tagList, rawTags, _, _ := parseTags(lit)
+		fields = append(fields, types.StructField{
+			Variable: v,
+			Tags:     parsedTags,
+			TagList:  tagList,
+			RawTags:  rawTags,
+		})
+	}
+	return fields
+}
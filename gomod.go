@@ -0,0 +1,415 @@
+package astra
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goMod is a minimal representation of go.mod file, enough to find sources of dependencies.
+type goMod struct {
+	Dir     string            // Directory, where go.mod is placed.
+	Path    string            // Module path from `module` directive.
+	Require map[string]string // Module path to version.
+	Replace map[string]modReplace
+
+	overlay overlay // Files, which replace files on disk, when directories of packages are searched.
+}
+
+type modReplace struct {
+	Path    string // Module path or local directory.
+	Version string // Empty for local directories.
+}
+
+// Searches go.mod in dir and all its parents.
+func findGoMod(ov overlay, dir string) (*goMod, error) {
+	for {
+		data, err := ov.readFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return parseGoMod(ov, dir, data)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parseGoMod(ov overlay, dir string, data []byte) (*goMod, error) {
+	mod := &goMod{
+		Dir:     dir,
+		Require: make(map[string]string),
+		Replace: make(map[string]modReplace),
+		overlay: ov,
+	}
+	err := forEachDirective(data, func(n int, fields []string) error {
+		switch fields[0] {
+		case "module":
+			if len(fields) != 2 {
+				return fmt.Errorf("go.mod:%d: malformed module directive", n)
+			}
+			mod.Path = fields[1]
+		case "require":
+			if len(fields) < 3 {
+				return fmt.Errorf("go.mod:%d: malformed require directive", n)
+			}
+			mod.Require[fields[1]] = fields[2]
+		case "replace":
+			modPath, r, ok := parseReplace(fields)
+			if !ok {
+				return fmt.Errorf("go.mod:%d: malformed replace directive", n)
+			}
+			mod.Replace[modPath] = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mod.Path == "" {
+		return nil, fmt.Errorf("%s: no module directive", filepath.Join(dir, "go.mod"))
+	}
+	return mod, nil
+}
+
+// Parses fields of replace directive: `replace path [version] => path [version]`.
+func parseReplace(fields []string) (string, modReplace, bool) {
+	arrow := -1
+	for i := range fields {
+		if fields[i] == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 2 || arrow+1 >= len(fields) {
+		return "", modReplace{}, false
+	}
+	r := modReplace{Path: fields[arrow+1]}
+	if arrow+2 < len(fields) {
+		r.Version = fields[arrow+2]
+	}
+	return fields[1], r, true
+}
+
+// goWork is a minimal representation of go.work file.
+type goWork struct {
+	Dir     string   // Directory, where go.work is placed.
+	Modules []*goMod // Modules from `use` directives.
+	Replace map[string]modReplace
+
+	overlay overlay
+}
+
+// Searches go.work in the same way as go command does: GOWORK environment variable is used, when it is set,
+// `GOWORK=off` disables workspaces, otherwise go.work is searched in dir and all its parents.
+func findGoWork(ov overlay, dir string) (*goWork, error) {
+	switch env := os.Getenv("GOWORK"); env {
+	case "off":
+		return nil, nil
+	case "":
+	default:
+		data, err := ov.readFile(env)
+		if err != nil {
+			return nil, fmt.Errorf("can not read GOWORK: %v", err)
+		}
+		return parseGoWork(ov, filepath.Dir(env), data)
+	}
+	for {
+		data, err := ov.readFile(filepath.Join(dir, "go.work"))
+		if err == nil {
+			return parseGoWork(ov, dir, data)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parseGoWork(ov overlay, dir string, data []byte) (*goWork, error) {
+	work := &goWork{Dir: dir, Replace: make(map[string]modReplace), overlay: ov}
+	err := forEachDirective(data, func(n int, fields []string) error {
+		switch fields[0] {
+		case "replace":
+			modPath, r, ok := parseReplace(fields)
+			if !ok {
+				return fmt.Errorf("go.work:%d: malformed replace directive", n)
+			}
+			work.Replace[modPath] = r
+			return nil
+		case "use":
+		default:
+			return nil
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("go.work:%d: malformed use directive", n)
+		}
+		modDir := filepath.FromSlash(fields[1])
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(dir, modDir)
+		}
+		modData, err := ov.readFile(filepath.Join(modDir, "go.mod"))
+		if err != nil {
+			return fmt.Errorf("go.work:%d: %v", n, err)
+		}
+		mod, err := parseGoMod(ov, modDir, modData)
+		if err != nil {
+			return err
+		}
+		work.Modules = append(work.Modules, mod)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+// Returns directory of package importPath. Packages of workspace modules are taken from their directories,
+// replace directives of go.work override replace directives of modules, other packages are dependencies of workspace modules.
+func (w *goWork) packageDir(importPath string) string {
+	var (
+		dir     string
+		modPath string
+	)
+	for _, mod := range w.Modules {
+		if len(mod.Path) <= len(modPath) {
+			continue
+		}
+		if d, ok := mod.localPackageDir(importPath); ok {
+			dir, modPath = d, mod.Path
+		}
+	}
+	if dir != "" {
+		return dir
+	}
+	if modPath := longestModulePrefix(importPath, replacedModules(w.Replace)); modPath != "" {
+		return replacedPackageDir(w.Dir, modPath, w.Replace[modPath], importPath)
+	}
+	for _, mod := range w.Modules {
+		if dir := mod.packageDir(importPath); dir != "" && w.overlay.isDir(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// Returns directories of workspace modules.
+func (w *goWork) moduleDirs() []string {
+	dirs := make([]string, len(w.Modules))
+	for i := range w.Modules {
+		dirs[i] = w.Modules[i].Dir
+	}
+	return dirs
+}
+
+// Calls fn for each directive of go.mod or go.work with its line number. Directives from blocks are
+// passed with the name of the block as the first field, like `require ( ... )` is passed as `require path version`.
+func forEachDirective(data []byte, fn func(line int, fields []string) error) error {
+	block := ""
+	for n, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := modFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if err := fn(n+1, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Splits line of go.mod by spaces, unquoting quoted fields.
+func modFields(line string) []string {
+	fields := strings.Fields(line)
+	for i := range fields {
+		if s, err := strconv.Unquote(fields[i]); err == nil {
+			fields[i] = s
+		}
+	}
+	return fields
+}
+
+// Returns directory with sources of package importPath, that is provided by this module or its dependencies.
+// Packages of nested modules and required modules with longer paths do not belong to this module.
+func (m *goMod) packageDir(importPath string) string {
+	modPath := m.longestModulePrefix(importPath)
+	if dir, ok := m.localPackageDir(importPath); ok && len(m.Path) > len(modPath) {
+		return dir
+	}
+	vendored := filepath.Join(m.Dir, "vendor", filepath.FromSlash(importPath))
+	if m.overlay.isDir(vendored) {
+		return vendored
+	}
+	if modPath == "" {
+		return ""
+	}
+	if r, ok := m.Replace[modPath]; ok {
+		return replacedPackageDir(m.Dir, modPath, r, importPath)
+	}
+	return cachedPackageDir(modPath, m.Require[modPath], importPath)
+}
+
+// Returns directory of package importPath from module modPath, which is replaced with r.
+// Local replacements are relative to dir with go.mod or go.work.
+func replacedPackageDir(dir, modPath string, r modReplace, importPath string) string {
+	if isLocalModPath(r.Path) {
+		root := r.Path
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(dir, root)
+		}
+		pkgDir, _ := pathInModule(modPath, root, importPath)
+		return pkgDir
+	}
+	return cachedPackageDir(r.Path, r.Version, r.Path+strings.TrimPrefix(importPath, modPath))
+}
+
+// Returns directory of package importPath from module cache.
+func cachedPackageDir(modPath, version, importPath string) string {
+	if version == "" {
+		return ""
+	}
+	root := filepath.Join(moduleCacheDir(), escapeModulePath(modPath)+"@"+version)
+	dir, _ := pathInModule(modPath, root, importPath)
+	return dir
+}
+
+// Maps importPath to directory of this module, if package belongs to it and is not a part of nested module.
+func (m *goMod) localPackageDir(importPath string) (string, bool) {
+	dir, ok := pathInModule(m.Path, m.Dir, importPath)
+	if !ok || inNestedModule(m.overlay, m.Dir, dir) {
+		return "", false
+	}
+	return dir, true
+}
+
+// Returns import path of package in dir, which is placed inside of the module.
+// Packages from vendor directory have import paths without vendor prefix.
+func (m *goMod) importPath(dir string) (string, bool) {
+	rel, ok := relPath(m.Dir, dir)
+	if !ok {
+		return "", false
+	}
+	if rel == "vendor" || strings.HasPrefix(rel, "vendor/") {
+		return trimVendor(rel), true
+	}
+	if rel == "" {
+		return m.Path, true
+	}
+	return m.Path + "/" + rel, true
+}
+
+func (m *goMod) longestModulePrefix(importPath string) string {
+	return longestModulePrefix(importPath, m.Require, replacedModules(m.Replace))
+}
+
+// Returns the longest module path from keys of modules, which is importPath or its prefix.
+func longestModulePrefix(importPath string, modules ...map[string]string) (modPath string) {
+	for _, deps := range modules {
+		for p := range deps {
+			if len(p) > len(modPath) && (importPath == p || strings.HasPrefix(importPath, p+"/")) {
+				modPath = p
+			}
+		}
+	}
+	return modPath
+}
+
+func replacedModules(replaces map[string]modReplace) map[string]string {
+	m := make(map[string]string, len(replaces))
+	for p := range replaces {
+		m[p] = ""
+	}
+	return m
+}
+
+// Maps importPath to directory, if package belongs to module modPath with root in dir.
+func pathInModule(modPath, dir, importPath string) (string, bool) {
+	if importPath == modPath {
+		return dir, true
+	}
+	if strings.HasPrefix(importPath, modPath+"/") {
+		return filepath.Join(dir, filepath.FromSlash(importPath[len(modPath)+1:])), true
+	}
+	return "", false
+}
+
+// Checks that some directory between root (exclusive) and dir (inclusive) has go.mod.
+func inNestedModule(ov overlay, root, dir string) bool {
+	for dir != root && len(dir) > len(root) {
+		if ov.fileExists(filepath.Join(dir, "go.mod")) {
+			return true
+		}
+		dir = filepath.Dir(dir)
+	}
+	return false
+}
+
+// Returns slash-separated path of dir relative to root, if dir is inside of root. Path of root itself is empty.
+func relPath(root, dir string) (string, bool) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Removes everything up to the last vendor element of slash-separated path.
+func trimVendor(p string) string {
+	p = "/" + p
+	if i := strings.LastIndex(p, "/vendor/"); i >= 0 {
+		return p[i+len("/vendor/"):]
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+func isLocalModPath(p string) bool {
+	return filepath.IsAbs(p) || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || p == "." || p == ".."
+}
+
+// Returns directory of module cache: GOMODCACHE or pkg/mod of the first GOPATH entry, as go command does it.
+func moduleCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	// build.Default.GOPATH is $HOME/go, when GOPATH is not set
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 || gopath[0] == "" {
+		return ""
+	}
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+// Escapes module path in the same way as module cache does: upper case letters are replaced with '!' and lower case letter.
+func escapeModulePath(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,269 @@
+package astra
+
+import (
+	"go/token"
+	gotypes "go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// ImportManager hands out names of imports for generated code.
+// It is seeded with imports and top-level declarations of the file, so new imports do not collide
+// with existing imports, declarations, builtins and keywords. Existing imports of the same package are reused.
+// Only imports, which were requested, are rendered, so unused imports of the seed file are dropped.
+type ImportManager struct {
+	// Imports with this prefix of path are placed to separate group after third-party imports,
+	// as `goimports -local` does it.
+	LocalPrefix string
+	// Resolver finds sources of packages of new imports to take their names.
+	// When it is nil, sources are searched from the working directory.
+	Resolver *Resolver
+
+	imports  []*types.Import
+	used     map[*types.Import]bool
+	reserved map[string]bool
+}
+
+// NewImportManager returns ImportManager, which is seeded with imports and declarations of file. File may be nil.
+func NewImportManager(file *types.File) *ImportManager {
+	m := &ImportManager{
+		used:     make(map[*types.Import]bool),
+		reserved: make(map[string]bool),
+	}
+	if file == nil {
+		return m
+	}
+	for _, imp := range file.Imports {
+		if imp != nil {
+			m.imports = append(m.imports, imp)
+		}
+	}
+	for i := range file.Constants {
+		m.Reserve(file.Constants[i].Name)
+	}
+	for i := range file.Vars {
+		m.Reserve(file.Vars[i].Name)
+	}
+	for i := range file.Interfaces {
+		m.Reserve(file.Interfaces[i].Name)
+	}
+	for i := range file.Structures {
+		m.Reserve(file.Structures[i].Name)
+	}
+	for i := range file.Functions {
+		m.Reserve(file.Functions[i].Name)
+	}
+	for i := range file.Types {
+		m.Reserve(file.Types[i].Name)
+	}
+	return m
+}
+
+// Reserve marks names as used by declarations, so imports will not get them.
+// Names, which were already given to imports, are not changed.
+func (m *ImportManager) Reserve(names ...string) {
+	for _, name := range names {
+		if name != "" && name != "_" {
+			m.reserved[name] = true
+		}
+	}
+}
+
+// Import returns name, which qualifies identifiers of package with importPath in generated code, and marks import as used.
+// If package is imported with dot, name is empty.
+// New import gets name of the package, when it is taken, numeric suffix is added: `errors2`, `errors3`.
+func (m *ImportManager) Import(importPath string) string {
+	return m.ImportAs(importPath, "")
+}
+
+// ImportAs works like Import, but new import gets preferred name, when it is a free identifier.
+// Existing imports of the package are reused with their names.
+func (m *ImportManager) ImportAs(importPath, name string) string {
+	return m.importOf(importPath, name).LocalName()
+}
+
+func (m *ImportManager) importOf(importPath, name string) *types.Import {
+	if imp := m.find(importPath); imp != nil {
+		m.used[imp] = true
+		return imp
+	}
+	realName, guessed := packageName(m.nameResolver(), importPath)
+	if importPath == "C" {
+		realName, guessed = "C", false
+	}
+	if !token.IsIdentifier(name) {
+		name = realName
+	}
+	if !token.IsIdentifier(name) {
+		name = "pkg"
+	}
+	if importPath != "C" {
+		base := name
+		for n := 2; m.nameUsed(name); n++ {
+			name = base + strconv.Itoa(n)
+		}
+	}
+	imp := &types.Import{
+		Base:     types.Base{Name: name},
+		Package:  importPath,
+		Kind:     types.ImportNormal,
+		RealName: realName,
+		Guessed:  guessed,
+	}
+	switch {
+	case importPath == "C":
+		imp.Kind = types.ImportCgo
+	case name != realName:
+		imp.Kind = types.ImportAliased
+	}
+	m.imports = append(m.imports, imp)
+	m.used[imp] = true
+	return imp
+}
+
+// Returns import of the package, which may qualify identifiers, blank imports are skipped.
+func (m *ImportManager) find(importPath string) *types.Import {
+	for _, imp := range m.imports {
+		if imp.Package == importPath && imp.Kind != types.ImportBlank && imp.Name != "_" {
+			return imp
+		}
+	}
+	return nil
+}
+
+// Returns Resolver, which finds names of packages.
+func (m *ImportManager) nameResolver() *Resolver {
+	if m.Resolver != nil {
+		return m.Resolver.nameResolver(m.Resolver.dir)
+	}
+	return nameResolver(".", nil)
+}
+
+func (m *ImportManager) nameUsed(name string) bool {
+	if m.reserved[name] || token.IsKeyword(name) || gotypes.Universe.Lookup(name) != nil {
+		return true
+	}
+	for _, imp := range m.imports {
+		if imp.LocalName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Qualify replaces imports of all qualified types in t with managed imports of the same packages and marks them as used.
+// It is useful for types from other files: their String and types.File.AstExpr use names, which are valid in generated code.
+func (m *ImportManager) Qualify(t types.Type) types.Type {
+	return types.MapType(t, func(t types.Type) types.Type {
+		if x, ok := t.(types.TImport); ok && x.Import != nil {
+			name := ""
+			if x.Import.Kind == types.ImportAliased {
+				name = x.Import.Name
+			}
+			x.Import = m.importOf(x.Import.Package, name)
+			return x
+		}
+		return t
+	})
+}
+
+// ParseType parses type expression, see ParseType. Qualifiers are resolved against imports of m,
+// so `pkg.Thing` gets import of `pkg` with its path. Found imports are marked as used.
+func (m *ImportManager) ParseType(expr string, options ...Option) (types.Type, error) {
+	t, err := ParseType(expr, m.imports, options...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Qualify(t), nil
+}
+
+// Imports returns used imports sorted by their paths.
+func (m *ImportManager) Imports() []*types.Import {
+	var imports []*types.Import
+	for _, imp := range m.imports {
+		if m.used[imp] {
+			imports = append(imports, imp)
+		}
+	}
+	sort.SliceStable(imports, func(i, j int) bool {
+		return imports[i].Package < imports[j].Package
+	})
+	return imports
+}
+
+// Block renders used imports in goimports style: `import "C"` goes first as a separate declaration,
+// then one block with groups of standard library, third-party and local imports, separated by empty lines.
+// Names are written only when they differ from names of packages.
+// Returns empty string, when no imports are used.
+func (m *ImportManager) Block() string {
+	var (
+		cgo    bool
+		groups [3][]string
+	)
+	for _, imp := range m.Imports() {
+		if imp.Kind == types.ImportCgo {
+			cgo = true
+			continue
+		}
+		groups[m.group(imp.Package)] = append(groups[m.group(imp.Package)], importLine(imp))
+	}
+	var lines []string
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, group...)
+	}
+	var b strings.Builder
+	if cgo {
+		b.WriteString("import \"C\"\n")
+		if len(lines) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	switch len(lines) {
+	case 0:
+	case 1:
+		b.WriteString("import " + lines[0] + "\n")
+	default:
+		b.WriteString("import (\n")
+		for _, line := range lines {
+			if line != "" {
+				b.WriteString("\t" + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// Returns index of group of import: 0 for standard library, 1 for third-party and 2 for local packages.
+func (m *ImportManager) group(importPath string) int {
+	switch {
+	case m.LocalPrefix != "" && strings.HasPrefix(importPath, m.LocalPrefix):
+		return 2
+	case !strings.Contains(strings.SplitN(importPath, "/", 2)[0], "."):
+		return 0
+	default:
+		return 1
+	}
+}
+
+func importLine(imp *types.Import) string {
+	quoted := strconv.Quote(imp.Package)
+	realName := imp.RealName
+	if realName == "" {
+		realName = GuessPackageName(imp.Package)
+	}
+	if imp.Kind == types.ImportDot || imp.Kind == types.ImportBlank || imp.Name != realName {
+		return imp.Name + " " + quoted
+	}
+	return quoted
+}
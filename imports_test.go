@@ -0,0 +1,140 @@
+package astra
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestImportManager(t *testing.T) {
+	file := &types.File{
+		Imports: []*types.Import{
+			{Base: types.Base{Name: "fmt"}, Package: "fmt", Kind: types.ImportNormal, RealName: "fmt"},
+			{Base: types.Base{Name: "errs"}, Package: "github.com/pkg/errors", Kind: types.ImportAliased, RealName: "errors"},
+			{Base: types.Base{Name: "_"}, Package: "github.com/lib/pq", Kind: types.ImportBlank, RealName: "pq"},
+			{Base: types.Base{Name: "."}, Package: "github.com/foo/dot", Kind: types.ImportDot, RealName: "dot"},
+			{Base: types.Base{Name: "unused"}, Package: "github.com/foo/unused", Kind: types.ImportNormal, RealName: "unused"},
+		},
+		Structures: []types.Struct{{Base: types.Base{Name: "errors"}}},
+		Functions:  []types.Function{{Base: types.Base{Name: "yaml"}}},
+	}
+	m := NewImportManager(file)
+	m.LocalPrefix = "github.com/vetcher/"
+	m.Reserve("context")
+	for _, c := range []struct{ path, name, expected string }{
+		{"fmt", "", "fmt"},
+		{"github.com/pkg/errors", "", "errs"},
+		{"errors", "", "errors2"},
+		{"context", "", "context2"},
+		{"gopkg.in/yaml.v3", "", "yaml2"},
+		{"github.com/lib/pq", "", "pq"},
+		{"github.com/foo/dot", "", ""},
+		{"github.com/mattn/go-sqlite3", "sql", "sql"},
+		{"github.com/foo/string", "", "string2"},
+		{"github.com/vetcher/go-astra/types", "break", "types"},
+		{"C", "", "C"},
+	} {
+		if actual := m.ImportAs(c.path, c.name); actual != c.expected {
+			t.Errorf("%s: expected %q, found %q", c.path, c.expected, actual)
+		}
+	}
+	if name := m.Import("errors"); name != "errors2" {
+		t.Errorf("import is not reused: %s", name)
+	}
+	expected := `import "C"
+
+import (
+	context2 "context"
+	errors2 "errors"
+	"fmt"
+
+	. "github.com/foo/dot"
+	string2 "github.com/foo/string"
+	"github.com/lib/pq"
+	sql "github.com/mattn/go-sqlite3"
+	errs "github.com/pkg/errors"
+	yaml2 "gopkg.in/yaml.v3"
+
+	"github.com/vetcher/go-astra/types"
+)
+`
+	if actual := m.Block(); actual != expected {
+		t.Errorf("unexpected block:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestImportManagerQualify(t *testing.T) {
+	m := NewImportManager(&types.File{Imports: []*types.Import{
+		{Base: types.Base{Name: "ctx"}, Package: "context", Kind: types.ImportAliased, RealName: "context"},
+	}})
+	other := &types.Import{Base: types.Base{Name: "context"}, Package: "context", Kind: types.ImportNormal, RealName: "context"}
+	bytes := &types.Import{Base: types.Base{Name: "b"}, Package: "bytes", Kind: types.ImportAliased, RealName: "bytes"}
+	typ := types.TMap{
+		Key:   types.TName{TypeName: "string"},
+		Value: types.TArray{IsSlice: true, Next: types.TImport{Import: other, Next: types.TName{TypeName: "Context"}}},
+	}
+	if actual, expected := m.Qualify(typ).String(), "map[string][]ctx.Context"; actual != expected {
+		t.Errorf("expected %s, found %s", expected, actual)
+	}
+	ptr := types.TPointer{NumberOfPointers: 1, Next: types.TImport{Import: bytes, Next: types.TName{TypeName: "Buffer"}}}
+	if actual, expected := m.Qualify(ptr).String(), "*b.Buffer"; actual != expected {
+		t.Errorf("expected %s, found %s", expected, actual)
+	}
+	if other.Name != "context" {
+		t.Error("original import is changed")
+	}
+	if actual, expected := m.Block(), "import (\n\tb \"bytes\"\n\tctx \"context\"\n)\n"; actual != expected {
+		t.Errorf("expected %q, found %q", expected, actual)
+	}
+	if NewImportManager(nil).Block() != "" {
+		t.Error("empty manager renders imports")
+	}
+}
+
+func TestImportManagerParseType(t *testing.T) {
+	m := NewImportManager(&types.File{Imports: []*types.Import{
+		{Base: types.Base{Name: "ctx"}, Package: "context", Kind: types.ImportAliased, RealName: "context"},
+		{Base: types.Base{Name: "unused"}, Package: "github.com/foo/unused", Kind: types.ImportNormal, RealName: "unused"},
+	}})
+	m.Import("github.com/foo/pkg")
+	typ, err := m.ParseType("map[pkg.Key]func(ctx.Context) [pkg.Size]byte")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := types.TypeImport(typ.(types.TMap).Key); imp == nil || imp.Package != "github.com/foo/pkg" {
+		t.Errorf("unexpected import of key: %#v", imp)
+	}
+	if actual, expected := m.Block(), "import (\n\tctx \"context\"\n\n\t\"github.com/foo/pkg\"\n)\n"; actual != expected {
+		t.Errorf("expected %q, found %q", expected, actual)
+	}
+	if _, err := m.ParseType("unknown.Thing"); err == nil {
+		t.Error("qualifier, which is not imported, is resolved")
+	}
+}
+
+func TestImportManagerNames(t *testing.T) {
+	dir := t.TempDir()
+	r, err := Config{Overlay: map[string][]byte{
+		filepath.Join(dir, "go.mod"):        []byte("module example.com/m\n"),
+		filepath.Join(dir, "pkg", "pkg.go"): []byte("package other\n"),
+	}}.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewImportManager(nil)
+	m.Resolver = r
+	for path, expected := range map[string]string{
+		"example.com/m/pkg":           "other",
+		"gopkg.in/yaml.v3":            "yaml",
+		"github.com/mattn/go-sqlite3": "sqlite3",
+	} {
+		if actual := m.Import(path); actual != expected {
+			t.Errorf("%s: expected %q, found %q", path, expected, actual)
+		}
+	}
+	expected := "import (\n\t\"example.com/m/pkg\"\n\t\"github.com/mattn/go-sqlite3\"\n\t\"gopkg.in/yaml.v3\"\n)\n"
+	if actual := m.Block(); actual != expected {
+		t.Errorf("expected %q, found %q", expected, actual)
+	}
+}
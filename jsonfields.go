@@ -0,0 +1,254 @@
+package astra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Struct, which fields are visited by JSONFields.
+type jsonStruct struct {
+	file  *types.File
+	s     types.Struct
+	key   string // Import path and name of structure.
+	index []int
+}
+
+// Underlying type of field, which is enough for rules of encoders.
+type underlyingType struct {
+	file  *types.File
+	s     *types.Struct // Not nil, when underlying type is a structure.
+	key   string
+	basic string // Name of underlying builtin type.
+}
+
+// JSONFields returns fields of structure, as encoding/json encodes and decodes them, in the same order:
+// names from `json` tags or Go names, fields with `-` tag and unexported fields are skipped,
+// fields of embedded structures are promoted by the same dominance rules.
+// Structures from other packages are loaded by resolver, it may be nil, when s and embedded structures are local.
+func JSONFields(file *types.File, s types.Struct, resolver *Resolver) ([]types.JSONField, error) {
+	var (
+		current, next = []jsonStruct(nil), []jsonStruct{{file: file, s: s, key: "." + s.Name}}
+		count         map[string]int
+		nextCount     = map[string]int{}
+		visited       = map[string]bool{}
+		fields        []types.JSONField
+	)
+	for len(next) > 0 {
+		current, next = next, nil
+		count, nextCount = nextCount, map[string]int{}
+		for _, js := range current {
+			if visited[js.key] {
+				continue
+			}
+			visited[js.key] = true
+			for i, sf := range js.s.Fields {
+				embedded := sf.Name == ""
+				name := sf.Name
+				if embedded {
+					name = embeddedName(sf.Type)
+				}
+				var (
+					t   underlyingType
+					err error
+				)
+				tag := sf.Tag("json")
+				// Underlying type is required only to flatten embedded structures and to check `string` option.
+				if embedded || tag != nil && tag.HasOption("string") {
+					t, err = underlyingOf(js.file, derefOnce(sf.Type), resolver)
+					if err != nil {
+						return nil, fmt.Errorf("field %s of %s: %v", name, js.s.Name, err)
+					}
+				}
+				if embedded {
+					if !isExportedName(name) && t.s == nil {
+						continue
+					}
+				} else if !isExportedName(name) {
+					continue
+				}
+				var tagName string
+				if tag != nil {
+					if tag.Value == "-" {
+						continue
+					}
+					if isValidJSONTag(tag.Name) {
+						tagName = tag.Name
+					}
+				}
+				index := append(append([]int(nil), js.index...), i)
+				if tagName != "" || !embedded || t.s == nil {
+					field := types.JSONField{
+						Name:   tagName,
+						Index:  index,
+						Field:  sf,
+						Tagged: tagName != "",
+					}
+					if field.Name == "" {
+						field.Name = name
+					}
+					if tag != nil {
+						field.OmitEmpty = tag.HasOption("omitempty")
+						field.OmitZero = tag.HasOption("omitzero")
+						field.String = tag.HasOption("string") && jsonQuotable[t.basic]
+					}
+					fields = append(fields, field)
+					if count[js.key] > 1 {
+						// Structure is embedded several times on the same depth, so field annihilates itself.
+						fields = append(fields, field)
+					}
+					continue
+				}
+				nextCount[t.key]++
+				if nextCount[t.key] == 1 {
+					next = append(next, jsonStruct{file: t.file, s: *t.s, key: t.key, index: index})
+				}
+			}
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		x, y := fields[i], fields[j]
+		if x.Name != y.Name {
+			return x.Name < y.Name
+		}
+		if len(x.Index) != len(y.Index) {
+			return len(x.Index) < len(y.Index)
+		}
+		if x.Tagged != y.Tagged {
+			return x.Tagged
+		}
+		return indexLess(x.Index, y.Index)
+	})
+	// Only dominant field is kept from fields with the same name.
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		for advance = 1; i+advance < len(fields) && fields[i+advance].Name == fields[i].Name; advance++ {
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantJSONField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+	fields = out
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].Index, fields[j].Index)
+	})
+	return fields, nil
+}
+
+// Fields are sorted by depth and tag, so the first field is dominant, when it is the only one on its depth with its tag.
+func dominantJSONField(fields []types.JSONField) (types.JSONField, bool) {
+	if len(fields) > 1 && len(fields[0].Index) == len(fields[1].Index) && fields[0].Tagged == fields[1].Tagged {
+		return types.JSONField{}, false
+	}
+	return fields[0], true
+}
+
+func indexLess(x, y []int) bool {
+	for k, xik := range x {
+		if k >= len(y) {
+			return false
+		}
+		if xik != y[k] {
+			return xik < y[k]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// Resolves named type to its underlying type. Types from other packages are loaded by resolver.
+func underlyingOf(file *types.File, t types.Type, resolver *Resolver) (underlyingType, error) {
+	path := ""
+	// Named types may be declared through other named types, depth is limited to break cycles.
+	for depth := 0; depth < 100; depth++ {
+		switch x := t.(type) {
+		case types.TImport:
+			if x.Import == nil {
+				return underlyingType{}, ErrNotImportedType
+			}
+			if resolver == nil {
+				return underlyingType{}, fmt.Errorf("can not resolve %s: resolver is not set", x.String())
+			}
+			pkg, err := resolver.Package(x.Import.Package)
+			if err != nil {
+				return underlyingType{}, err
+			}
+			file, path, t = pkg, x.Import.Package, x.Next
+		case types.TName:
+			if types.IsBuiltinTypeString(x.TypeName) {
+				return underlyingType{basic: x.TypeName}, nil
+			}
+			switch decl := file.FindDecl(x.TypeName).(type) {
+			case *types.Struct:
+				return underlyingType{file: file, s: decl, key: path + "." + decl.Name}, nil
+			case *types.FileType:
+				t = decl.Type
+			default:
+				// Interfaces, type parameters and unknown types.
+				return underlyingType{}, nil
+			}
+		case types.Struct:
+			return underlyingType{file: file, s: &x}, nil
+		default:
+			return underlyingType{}, nil
+		}
+	}
+	return underlyingType{}, nil
+}
+
+// Removes one pointer from type: encoding/json dereferences embedded and quoted fields only once.
+func derefOnce(t types.Type) types.Type {
+	p, ok := t.(types.TPointer)
+	if !ok {
+		return t
+	}
+	if p.NumberOfPointers == 1 {
+		return p.Next
+	}
+	return types.TPointer{Next: p.Next, NumberOfPointers: p.NumberOfPointers - 1}
+}
+
+// Returns name of embedded field: name of its type without package and type arguments.
+func embeddedName(t types.Type) string {
+	name := types.TypeName(t)
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+func isExportedName(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// The same rule for names in tags, as encoding/json uses.
+func isValidJSONTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// Builtin types, which values may be encoded as strings with `string` option.
+var jsonQuotable = map[string]bool{
+	"bool": true, "string": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true, "byte": true,
+	"float32": true, "float64": true,
+}
@@ -0,0 +1,209 @@
+package astra
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	gotypes "go/types"
+	"sort"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+var ErrUnknownSize = errors.New("size of type is unknown")
+
+// Layouter calculates sizes, alignments and offsets of types, as gc compiler does it for GOARCH.
+// Types are converted to go/types types, so the same rules, as in go/types.SizesFor, are used.
+type Layouter struct {
+	sizes    gotypes.Sizes
+	resolver *Resolver
+	pkg      *gotypes.Package // Owner of unexported fields of converted structures.
+}
+
+// NewLayouter returns Layouter for goarch, build.Default.GOARCH is used, when it is empty.
+// Resolver is used to load types from other packages, it may be nil, when all types are local.
+func NewLayouter(goarch string, resolver *Resolver) (*Layouter, error) {
+	if goarch == "" {
+		goarch = build.Default.GOARCH
+	}
+	sizes := gotypes.SizesFor("gc", goarch)
+	if sizes == nil {
+		return nil, fmt.Errorf("unknown GOARCH %s", goarch)
+	}
+	return &Layouter{
+		sizes:    sizes,
+		resolver: resolver,
+		pkg:      gotypes.NewPackage("layout", "layout"),
+	}, nil
+}
+
+// SizeOf returns size and alignment of type, which is declared in file.
+func (l *Layouter) SizeOf(file *types.File, t types.Type) (size, align int64, err error) {
+	gt, err := l.goType(file, t, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	return l.sizes.Sizeof(gt), l.sizes.Alignof(gt), nil
+}
+
+// StructLayout returns offsets, sizes and alignments of fields of structure, which is declared in file,
+// padding between them and the order of fields, which minimizes size of structure.
+func (l *Layouter) StructLayout(file *types.File, s types.Struct) (*types.StructLayout, error) {
+	fields := make([]*gotypes.Var, len(s.Fields))
+	for i, field := range s.Fields {
+		t, err := l.goType(file, field.Type, 0)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %v", displayFieldName(field), s.Name, err)
+		}
+		fields[i] = gotypes.NewField(0, l.pkg, layoutFieldName(field, i), t, false)
+	}
+	st := gotypes.NewStruct(fields, nil)
+	layout := &types.StructLayout{
+		Size:  l.sizes.Sizeof(st),
+		Align: l.sizes.Alignof(st),
+	}
+	offsets := l.sizes.Offsetsof(fields)
+	var end int64
+	for i, field := range fields {
+		fl := types.FieldLayout{
+			Name:    displayFieldName(s.Fields[i]),
+			Offset:  offsets[i],
+			Size:    l.sizes.Sizeof(field.Type()),
+			Align:   l.sizes.Alignof(field.Type()),
+			Padding: offsets[i] - end,
+		}
+		end = fl.Offset + fl.Size
+		layout.Padding += fl.Padding
+		layout.Fields = append(layout.Fields, fl)
+	}
+	layout.TrailingPadding = layout.Size - end
+	layout.Padding += layout.TrailingPadding
+
+	order := l.optimalOrder(fields)
+	optimal := make([]*gotypes.Var, len(order))
+	for i, j := range order {
+		optimal[i] = fields[j]
+		layout.OptimalOrder = append(layout.OptimalOrder, layout.Fields[j].Name)
+	}
+	layout.OptimalSize = l.sizes.Sizeof(gotypes.NewStruct(optimal, nil))
+	return layout, nil
+}
+
+// Returns indexes of fields in order, which minimizes padding: zero sized fields first,
+// because zero sized last field is padded, then by alignment and size in descending order.
+func (l *Layouter) optimalOrder(fields []*gotypes.Var) []int {
+	order := make([]int, len(fields))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		x, y := fields[order[i]].Type(), fields[order[j]].Type()
+		sx, sy := l.sizes.Sizeof(x), l.sizes.Sizeof(y)
+		if (sx == 0) != (sy == 0) {
+			return sx == 0
+		}
+		if ax, ay := l.sizes.Alignof(x), l.sizes.Alignof(y); ax != ay {
+			return ax > ay
+		}
+		return sx > sy
+	})
+	return order
+}
+
+// Converts type to go/types type, which has the same size and alignment.
+// Elements of pointers, slices, maps, channels and functions do not affect size, so they are not converted.
+func (l *Layouter) goType(file *types.File, t types.Type, depth int) (gotypes.Type, error) {
+	if depth > 100 {
+		return nil, fmt.Errorf("%v: type is too deep or recursive", ErrUnknownSize)
+	}
+	word := gotypes.Typ[gotypes.UnsafePointer]
+	switch x := t.(type) {
+	case types.TName:
+		if basic := basicTypeByName(x.TypeName); basic != nil {
+			return basic, nil
+		}
+		switch decl := file.FindDecl(x.TypeName).(type) {
+		case *types.Struct:
+			return l.structType(file, *decl, depth)
+		case *types.FileType:
+			return l.goType(file, decl.Type, depth+1)
+		case *types.Interface:
+			return gotypes.NewInterfaceType(nil, nil), nil
+		}
+		if x.TypeName == "error" || x.TypeName == "any" {
+			return gotypes.NewInterfaceType(nil, nil), nil
+		}
+		return nil, fmt.Errorf("%v: %s", ErrUnknownSize, x.TypeName)
+	case types.TImport:
+		if x.Import != nil && x.Import.Package == "unsafe" {
+			return word, nil
+		}
+		if x.Import == nil {
+			return nil, ErrNotImportedType
+		}
+		if l.resolver == nil {
+			return nil, fmt.Errorf("can not resolve %s: resolver is not set", x.String())
+		}
+		pkg, err := l.resolver.Package(x.Import.Package)
+		if err != nil {
+			return nil, err
+		}
+		return l.goType(pkg, x.Next, depth+1)
+	case types.TPointer, types.TMap, types.TChan, types.Function, *types.Function:
+		return word, nil
+	case types.TArray:
+		if x.IsSlice {
+			return gotypes.NewSlice(word), nil
+		}
+		if x.IsEllipsis || x.LenExpr != "" || x.ArrayLen < 0 {
+			return nil, fmt.Errorf("%v: length of array %s", ErrUnknownSize, x.String())
+		}
+		elem, err := l.goType(file, x.Next, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return gotypes.NewArray(elem, int64(x.ArrayLen)), nil
+	case types.TInterface:
+		return gotypes.NewInterfaceType(nil, nil), nil
+	case types.Struct:
+		return l.structType(file, x, depth)
+	case *types.Struct:
+		return l.structType(file, *x, depth)
+	}
+	return nil, fmt.Errorf("%v: %v", ErrUnknownSize, t)
+}
+
+func (l *Layouter) structType(file *types.File, s types.Struct, depth int) (gotypes.Type, error) {
+	fields := make([]*gotypes.Var, len(s.Fields))
+	for i, field := range s.Fields {
+		t, err := l.goType(file, field.Type, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = gotypes.NewField(0, l.pkg, layoutFieldName(field, i), t, false)
+	}
+	return gotypes.NewStruct(fields, nil), nil
+}
+
+// Names of fields of converted structures should be unique, blank fields and embedded fields are renamed.
+func layoutFieldName(field types.StructField, i int) string {
+	if field.Name == "" || field.Name == "_" {
+		return fmt.Sprintf("_%d", i)
+	}
+	return field.Name
+}
+
+func basicTypeByName(name string) gotypes.Type {
+	switch name {
+	case "byte":
+		return gotypes.Typ[gotypes.Byte]
+	case "rune":
+		return gotypes.Typ[gotypes.Rune]
+	}
+	for _, basic := range gotypes.Typ {
+		if basic.Name() == name && basic.Kind() != gotypes.UnsafePointer && basic.Info()&gotypes.IsUntyped == 0 {
+			return basic
+		}
+	}
+	return nil
+}
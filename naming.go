@@ -0,0 +1,76 @@
+package astra
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Splits Go name to words by case changes, underscores and dashes.
+// Upper case abbreviations are kept as one word: `HTTPServerID` is split to `HTTP`, `Server` and `ID`.
+func splitWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextIsLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// SnakeCase converts Go name to snake_case: `UserID` becomes `user_id`.
+func SnakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// KebabCase converts Go name to kebab-case: `UserID` becomes `user-id`.
+func KebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// CamelCase converts Go name to camelCase: `UserID` becomes `userId`.
+func CamelCase(name string) string {
+	words := splitWords(name)
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+		if i > 0 {
+			words[i] = upperFirst(words[i])
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// PascalCase converts Go name to PascalCase: `user_id` becomes `UserId`.
+func PascalCase(name string) string {
+	words := splitWords(name)
+	for i := range words {
+		words[i] = upperFirst(strings.ToLower(words[i]))
+	}
+	return strings.Join(words, "")
+}
+
+func upperFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
@@ -0,0 +1,275 @@
+package astra
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	astparser "go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// overlay replaces contents of files on disk: absolute path of file to its contents.
+// Files from overlay may not exist on disk. Nil overlay reads files from disk only.
+type overlay map[string][]byte
+
+// Returns overlay with absolute paths of files, relative paths are relative to the working directory.
+func newOverlay(files map[string][]byte) (overlay, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	abs := make(overlay, len(files))
+	for name, src := range files {
+		p, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("can not filepath.Abs: %v", err)
+		}
+		abs[p] = src
+	}
+	return abs, nil
+}
+
+func (o overlay) file(name string) ([]byte, bool) {
+	if len(o) == 0 {
+		return nil, false
+	}
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return nil, false
+	}
+	src, ok := o[p]
+	return src, ok
+}
+
+// Returns names of overlay files, which are placed directly in dir.
+func (o overlay) dir(dir string) []string {
+	if len(o) == 0 {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for p := range o {
+		if filepath.Dir(p) == dir {
+			names = append(names, filepath.Base(p))
+		}
+	}
+	return names
+}
+
+// Returns direct subdirectories of dir, which contain overlay files.
+func (o overlay) subdirs(dir string) []string {
+	if len(o) == 0 {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var subdirs []string
+	for p := range o {
+		rel, ok := relPath(dir, p)
+		if !ok || !strings.Contains(rel, "/") {
+			continue
+		}
+		sub := filepath.Join(dir, rel[:strings.Index(rel, "/")])
+		if !containsString(subdirs, sub) {
+			subdirs = append(subdirs, sub)
+		}
+	}
+	return subdirs
+}
+
+// Checks that some overlay file is placed in dir or its subdirectories.
+func (o overlay) hasDir(dir string) bool {
+	if len(o) == 0 {
+		return false
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for p := range o {
+		if rel, ok := relPath(dir, p); ok && rel != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads file from overlay or from disk.
+func (o overlay) readFile(name string) ([]byte, error) {
+	if src, ok := o.file(name); ok {
+		return src, nil
+	}
+	return ioutil.ReadFile(name)
+}
+
+func (o overlay) fileExists(name string) bool {
+	if _, ok := o.file(name); ok {
+		return true
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// Checks that directory exists on disk or contains overlay files.
+func (o overlay) isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir() || o.hasDir(path)
+}
+
+// Reads directory from disk and adds overlay files to it. Entries are sorted by name.
+func (o overlay) readDir(dir string) ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(dir)
+	names := o.dir(dir)
+	if err != nil && len(names) == 0 && !o.hasDir(dir) {
+		return nil, err
+	}
+	byName := make(map[string]os.FileInfo, len(infos)+len(names))
+	for _, info := range infos {
+		byName[info.Name()] = info
+	}
+	for _, name := range names {
+		src, _ := o.file(filepath.Join(dir, name))
+		byName[name] = overlayFileInfo{name: name, size: int64(len(src))}
+	}
+	result := make([]os.FileInfo, 0, len(byName))
+	for _, info := range byName {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result, nil
+}
+
+type overlayFileInfo struct {
+	name string
+	size int64
+}
+
+func (i overlayFileInfo) Name() string       { return i.name }
+func (i overlayFileInfo) Size() int64        { return i.size }
+func (i overlayFileInfo) Mode() os.FileMode  { return 0644 }
+func (i overlayFileInfo) ModTime() time.Time { return time.Time{} }
+func (i overlayFileInfo) IsDir() bool        { return false }
+func (i overlayFileInfo) Sys() interface{}   { return nil }
+
+// fileSystem is a source of files for loaders: disk with overlay or fs.FS.
+type fileSystem interface {
+	ReadDir(dir string) ([]os.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	Join(elem ...string) string
+	BuildContext() *build.Context
+}
+
+// diskFS reads files from disk and overlay.
+type diskFS struct {
+	overlay overlay
+}
+
+func (f diskFS) ReadDir(dir string) ([]os.FileInfo, error) { return f.overlay.readDir(dir) }
+func (f diskFS) ReadFile(name string) ([]byte, error)      { return f.overlay.readFile(name) }
+func (f diskFS) Join(elem ...string) string                { return filepath.Join(elem...) }
+
+func (f diskFS) BuildContext() *build.Context {
+	ctxt := build.Default
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		if src, ok := f.overlay.file(name); ok {
+			return ioutil.NopCloser(bytes.NewReader(src)), nil
+		}
+		return os.Open(name)
+	}
+	return &ctxt
+}
+
+// ioFS reads files from fs.FS, paths are slash-separated and relative to its root.
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) ReadDir(dir string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(f.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (f ioFS) ReadFile(name string) ([]byte, error) { return fs.ReadFile(f.fsys, name) }
+func (f ioFS) Join(elem ...string) string           { return path.Join(elem...) }
+
+func (f ioFS) BuildContext() *build.Context {
+	ctxt := build.Default
+	ctxt.JoinPath = path.Join
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		return f.fsys.Open(name)
+	}
+	return &ctxt
+}
+
+// Returns filter for parseDir, that accepts non-test .go files, which satisfy build constraints.
+func sourceFilterOf(sys fileSystem, dir string) func(os.FileInfo) bool {
+	ctxt := sys.BuildContext()
+	return func(info os.FileInfo) bool {
+		if strings.HasSuffix(info.Name(), "_test.go") {
+			return false
+		}
+		ok, err := ctxt.MatchFile(dir, info.Name())
+		return err == nil && ok
+	}
+}
+
+// Returns filter for parseDir, that accepts non-test .go files from disk and overlay, which satisfy build constraints.
+func sourceFilter(ov overlay, dir string) func(os.FileInfo) bool {
+	return sourceFilterOf(diskFS{overlay: ov}, dir)
+}
+
+// Works like parser.ParseDir, but reads files from sys and parses them concurrently, see parseGoFile.
+func parseDir(sys fileSystem, fset *token.FileSet, dir string, filter func(os.FileInfo) bool, mode astparser.Mode, opt Option) (map[string]*ast.Package, error) {
+	infos, err := sys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") || (filter != nil && !filter(info)) {
+			continue
+		}
+		filenames = append(filenames, sys.Join(dir, info.Name()))
+	}
+	files, err := parseGoFiles(fset, filenames, sys.ReadFile, mode, opt)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string]*ast.Package)
+	for i, file := range files {
+		pkg, ok := pkgs[file.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: file.Name.Name, Files: make(map[string]*ast.File)}
+			pkgs[file.Name.Name] = pkg
+		}
+		pkg.Files[filenames[i]] = file
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,52 @@
+package astra
+
+import (
+	"testing"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestGuessPackageName(t *testing.T) {
+	for importPath, expected := range map[string]string{
+		"fmt":                            "fmt",
+		"github.com/foo/bar/v2":          "bar",
+		"gopkg.in/yaml.v3":               "yaml",
+		"github.com/mattn/go-sqlite3":    "sqlite3",
+		"github.com/shopspring/decimal":  "decimal",
+		"github.com/segmentio/kafka-go":  "kafka",
+		"github.com/foo/go-bar-baz/v10":  "bar",
+		"v2":                             "v2",
+		"example.com/пакет":              "пакет",
+		"github.com/hashicorp/go-getter": "getter",
+	} {
+		if actual := GuessPackageName(importPath); actual != expected {
+			t.Errorf("%s: expected %s, found %s", importPath, expected, actual)
+		}
+	}
+}
+
+func TestFindImportByAlias(t *testing.T) {
+	file := &types.File{Imports: []*types.Import{
+		{Base: types.Base{Name: "_"}, Package: "github.com/lib/pq", Kind: types.ImportBlank, RealName: "pq"},
+		{Base: types.Base{Name: "."}, Package: "github.com/foo/dot", Kind: types.ImportDot, RealName: "dot"},
+		{Base: types.Base{Name: "yaml"}, Package: "gopkg.in/yaml.v3", Kind: types.ImportNormal, RealName: "yaml", Guessed: true},
+		{Base: types.Base{Name: "b"}, Package: "github.com/foo/bar/v2", Kind: types.ImportAliased, RealName: "bar", Guessed: true},
+	}}
+	for alias, expected := range map[string]string{
+		"yaml": "gopkg.in/yaml.v3",
+		"b":    "github.com/foo/bar/v2",
+		"bar":  "github.com/foo/bar/v2",
+		"_":    "",
+		"pq":   "",
+		"dot":  "",
+		"v2":   "",
+	} {
+		imp, err := findImportByAlias(file, alias)
+		switch {
+		case expected == "" && err == nil:
+			t.Errorf("%s: expect error, found %s", alias, imp.Package)
+		case expected != "" && (err != nil || imp.Package != expected):
+			t.Errorf("%s: expected %s, found %v, %v", alias, expected, imp, err)
+		}
+	}
+}
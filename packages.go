@@ -0,0 +1,199 @@
+package astra
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Package is a package, which was loaded by pattern.
+type Package struct {
+	ImportPath string      // Import path of the package. Directory is used, when package is outside of modules and GOPATH.
+	Dir        string      // Absolute path of directory with sources of the package.
+	File       *types.File // Merged file of all package sources, see GetPackage.
+}
+
+// LoadPackages loads packages, which match patterns, like go command does it.
+// Pattern with `...` suffix, like `./...` or `example.com/mod/...`, matches package and all packages in its subdirectories.
+// Other patterns are single packages. Patterns, which start with `.` or `/`, are directories, others are import paths,
+// which are resolved with Resolver of the working directory.
+// Directories `testdata`, `vendor` and ones with names, which start with `.` or `_`, are skipped, as well as nested modules.
+// When directories are matched inside of go.work workspace, only packages of workspace modules are loaded,
+// so `./...` in the directory of go.work spans all its modules.
+// Test files are not loaded, options are used for parsing every package.
+func LoadPackages(patterns []string, options ...Option) ([]Package, error) {
+	return loadPackages(nil, patterns, options...)
+}
+
+// Loads packages, which match patterns, files and directories are read from disk and overlay.
+func loadPackages(ov overlay, patterns []string, options ...Option) ([]Package, error) {
+	var (
+		dirs []string
+		seen = make(map[string]bool)
+	)
+	for _, pattern := range patterns {
+		matched, err := matchPackageDirs(ov, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pattern, err)
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("%s: no packages found", pattern)
+		}
+		for _, dir := range matched {
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	packages := make([]Package, 0, len(dirs))
+	for _, dir := range dirs {
+		resolver, err := newResolver(dir, ov, options...)
+		if err != nil {
+			return nil, err
+		}
+		f, err := resolver.parsePackageDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", dir, err)
+		}
+		if concatOptions(options).check(ResolveDotImports) {
+			err = resolver.ResolveDotImports(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", dir, err)
+			}
+		}
+		pkgPath, err := importPath(ov, dir)
+		if err != nil {
+			pkgPath = filepath.ToSlash(dir)
+		}
+		packages = append(packages, Package{ImportPath: pkgPath, Dir: dir, File: f})
+	}
+	return packages, nil
+}
+
+// Returns absolute paths of directories with packages, which match pattern.
+func matchPackageDirs(ov overlay, pattern string) ([]string, error) {
+	recursive := pattern == "..." || strings.HasSuffix(pattern, "/...")
+	root := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+	var (
+		dir string
+		err error
+	)
+	if isLocalPattern(pattern) {
+		if root == "" {
+			root = "."
+		}
+		dir, err = filepath.Abs(filepath.FromSlash(root))
+		if err != nil {
+			return nil, fmt.Errorf("can not filepath.Abs: %v", err)
+		}
+	} else {
+		resolver, err := newResolver(".", ov)
+		if err != nil {
+			return nil, err
+		}
+		dir, err = resolver.PackageDir(root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !recursive {
+		if !isPackageDir(ov, dir) {
+			return nil, nil
+		}
+		return []string{dir}, nil
+	}
+	work, err := findGoWork(ov, dir)
+	if err != nil {
+		return nil, err
+	}
+	var modules []string
+	if work != nil {
+		modules = work.moduleDirs()
+	}
+	var dirs []string
+	err = walkDirs(ov, dir, func(p string) bool {
+		if p != dir {
+			name := filepath.Base(p)
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return false
+			}
+			if ov.fileExists(filepath.Join(p, "go.mod")) && !containsString(modules, p) {
+				return false
+			}
+		}
+		if (work == nil || inModules(modules, p)) && isPackageDir(ov, p) {
+			dirs = append(dirs, p)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not walk dir: %v", err)
+	}
+	return dirs, nil
+}
+
+// Calls fn for dir and all its subdirectories in lexical order, including directories of overlay files.
+// Subdirectories are skipped, when fn returns false.
+func walkDirs(ov overlay, dir string, fn func(dir string) bool) error {
+	if !fn(dir) {
+		return nil
+	}
+	infos, err := ov.readDir(dir)
+	if err != nil {
+		return err
+	}
+	var subdirs []string
+	for _, info := range infos {
+		if info.IsDir() {
+			subdirs = append(subdirs, filepath.Join(dir, info.Name()))
+		}
+	}
+	for _, sub := range ov.subdirs(dir) {
+		if !containsString(subdirs, sub) {
+			subdirs = append(subdirs, sub)
+		}
+	}
+	sort.Strings(subdirs)
+	for _, sub := range subdirs {
+		if err := walkDirs(ov, sub, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Patterns of directories start with `.` or are absolute paths, other patterns are import paths.
+func isLocalPattern(pattern string) bool {
+	return pattern == "." || pattern == ".." || strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../") ||
+		path.IsAbs(pattern) || filepath.IsAbs(pattern)
+}
+
+// Checks that dir is one of modules or is placed inside of one of them.
+func inModules(modules []string, dir string) bool {
+	for _, mod := range modules {
+		if _, ok := relPath(mod, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks that directory has .go files, which are not tests and satisfy build constraints.
+func isPackageDir(ov overlay, dir string) bool {
+	files, err := ov.readDir(dir)
+	if err != nil {
+		return false
+	}
+	filter := sourceFilter(ov, dir)
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".go") && filter(file) {
+			return true
+		}
+	}
+	return false
+}
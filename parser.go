@@ -4,16 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/parser"
 	"go/token"
+	gotypes "go/types"
+	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
-	"github.com/fatih/structtag"
 	"github.com/vetcher/go-astra/types"
 )
 
@@ -36,6 +37,15 @@ const (
 	IgnoreVariables
 	IgnoreConstants
 	AllowAnyImportAliases
+	// Type-check sources with go/types and fill TypeInfo of variables, struct fields and types.
+	// Imported packages are loaded from sources with Resolver. Function bodies are parsed and checked too.
+	CheckTypes
+	// Parse bodies of functions and methods. By default loaders skip them, because astra does not inspect them,
+	// so syntax errors inside of bodies are not reported without this option.
+	KeepFuncBodies
+	// Qualify names of types, which are declared in dot-imported packages, with their imports, see Resolver.ResolveDotImports.
+	// Dot-imported packages are loaded from sources with Resolver.
+	ResolveDotImports
 )
 
 func concatOptions(ops []Option) (o Option) {
@@ -50,15 +60,26 @@ func (o Option) check(what Option) bool {
 }
 
 // Parses ast.File and return all top-level declarations.
+// Names of imported packages are found from the working directory.
 func ParseAstFile(file *ast.File, options ...Option) (*types.File, error) {
+	return parseAstFile(file, nameResolver(".", nil), options...)
+}
+
+// Parses ast.File, names of imported packages are found by r.
+func parseAstFile(file *ast.File, r *Resolver, options ...Option) (*types.File, error) {
 	opt := concatOptions(options)
 	f := &types.File{
 		Base: types.Base{
-			Name: file.Name.Name,
-			Docs: parseComments(file.Doc, opt),
+			Name:       file.Name.Name,
+			Docs:       parseComments(file.Doc, opt),
+			Comments:   parseCommentsModel(opt, nil, file.Doc, nil),
+			Directives: parseFileDirectives(file),
 		},
+		FloatingComments: parseFloatingComments(file, opt),
+		License:          parseLicense(file, opt),
+		IsGenerated:      ast.IsGenerated(file),
 	}
-	err := parseTopLevelDeclarations(file.Decls, f, opt)
+	err := parseTopLevelDeclarations(file.Decls, f, r, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -66,9 +87,14 @@ func ParseAstFile(file *ast.File, options ...Option) (*types.File, error) {
 	if err != nil {
 		return nil, err
 	}
+	types.WalkBases(f, markDeprecated)
 	return f, nil
 }
 
+func markDeprecated(b *types.Base) {
+	b.Deprecated = types.IsDeprecatedDoc(b.DocText())
+}
+
 func linkMethodsToStructs(f *types.File) error {
 	for i := range f.Methods {
 		structure, err := findStructByMethod(f, &f.Methods[i])
@@ -104,9 +130,9 @@ func parseComments(group *ast.CommentGroup, o Option) (comments []string) {
 	return
 }
 
-func parseTopLevelDeclarations(decls []ast.Decl, file *types.File, opt Option) error {
+func parseTopLevelDeclarations(decls []ast.Decl, file *types.File, r *Resolver, opt Option) error {
 	for i := range decls {
-		err := parseDeclaration(decls[i], file, opt)
+		err := parseDeclaration(decls[i], file, r, opt)
 		if err != nil {
 			return err
 		}
@@ -114,60 +140,179 @@ func parseTopLevelDeclarations(decls []ast.Decl, file *types.File, opt Option) e
 	return nil
 }
 
+// Names of packages are cached by import path and directories of go.work and go.mod, which are used to find them,
+// because the same import path may be replaced with different packages in different modules.
+type packageNameKey struct {
+	work       string
+	module     string // directory of resolver, when it is outside of modules
+	importPath string
+}
+
 var (
-	packagesPathNameCache = map[string]string{}
+	packagesPathNameCache = map[packageNameKey]packageNameInfo{}
 	mx                    sync.Mutex
+	nameResolvers         = map[string]*Resolver{}
 )
 
-func constructAliasName(spec *ast.ImportSpec) string {
-	if spec.Name != nil {
-		return spec.Name.Name
-	}
+// Returns name of import as it is written in the file, real name of imported package and kind of import.
+// Real name is guessed from import path, when sources of package are not found by r.
+func constructAliasName(spec *ast.ImportSpec, r *Resolver) (name, realName string, guessed bool, kind types.ImportKind) {
 	importPath := strings.Trim(spec.Path.Value, `"`)
+	if importPath == "C" {
+		// cgo pseudo package has no sources.
+		return "C", "C", false, types.ImportCgo
+	}
+	realName, guessed = packageName(r, importPath)
+	if spec.Name == nil {
+		return realName, realName, guessed, types.ImportNormal
+	}
+	switch spec.Name.Name {
+	case ".":
+		kind = types.ImportDot
+	case "_":
+		kind = types.ImportBlank
+	default:
+		kind = types.ImportAliased
+	}
+	return spec.Name.Name, realName, guessed, kind
+}
+
+type packageNameInfo struct {
+	name    string
+	guessed bool
+}
+
+// Returns name of package from its sources, which are found by r, or guesses it from import path, when sources are not found.
+func packageName(r *Resolver, importPath string) (name string, guessed bool) {
+	if r == nil {
+		return GuessPackageName(importPath), true
+	}
+	if len(r.overlay) > 0 {
+		// names depend on overlay, so they are not shared with other loads
+		r.namesMx.Lock()
+		defer r.namesMx.Unlock()
+		info, ok := r.names[importPath]
+		if !ok {
+			info = r.lookupPackageName(importPath)
+			r.names[importPath] = info
+		}
+		return info.name, info.guessed
+	}
+	key := r.packageNameKey(importPath)
 	mx.Lock()
 	defer mx.Unlock()
-	name, ok := packagesPathNameCache[importPath]
-	if ok {
-		return name
+	info, ok := packagesPathNameCache[key]
+	if !ok {
+		info = r.lookupPackageName(importPath)
+		packagesPathNameCache[key] = info
 	}
-	for _, p := range []string{build.Default.GOROOT, "vendor", build.Default.GOPATH} {
-		name = findPackageName(p, importPath)
-		if name != "" {
-			break
+	return info.name, info.guessed
+}
+
+func (r *Resolver) lookupPackageName(importPath string) packageNameInfo {
+	if dir, err := r.PackageDir(importPath); err == nil {
+		if name := findPackageName(r.overlay, dir); name != "" {
+			return packageNameInfo{name: name}
 		}
 	}
-	if name == "" {
-		name = constructAliasNameString(spec.Path.Value)
+	return packageNameInfo{name: GuessPackageName(importPath), guessed: true}
+}
+
+// Returns Resolver, which finds sources of packages, imported by files in dir, as go command does it.
+// Resolvers without overlay are shared, so go.mod and go.work files of every directory are read once.
+func nameResolver(dir string, ov overlay) *Resolver {
+	if len(ov) > 0 {
+		r, _ := newResolver(dir, ov)
+		return r
 	}
-	packagesPathNameCache[importPath] = name
-	return name
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	mx.Lock()
+	defer mx.Unlock()
+	r, ok := nameResolvers[abs]
+	if !ok {
+		r, _ = NewResolver(abs)
+		nameResolvers[abs] = r
+	}
+	return r
 }
 
-var importAliasReplacer = strings.NewReplacer("-", "")
+// Returns Resolver for names of packages, imported by files in dir, which reads files from the same overlay, as r.
+func (r *Resolver) nameResolver(dir string) *Resolver {
+	if len(r.overlay) > 0 && dir == r.dir {
+		return r
+	}
+	return nameResolver(dir, r.overlay)
+}
 
-func constructAliasNameString(str string) string {
-	name := path.Base(strings.Trim(str, `"`))
-	name = importAliasReplacer.Replace(name)
-	if types.BuiltinTypes[name] || types.BuiltinFunctions[name] {
-		name = "_" + name
+// GuessPackageName returns name of package by its import path with the same rules, as goimports uses:
+// major version suffix `/vN` is skipped, `go-` prefix is trimmed and name is cut at the first character,
+// which is not allowed in identifiers, so `gopkg.in/yaml.v3` is `yaml` and `github.com/mattn/go-sqlite3` is `sqlite3`.
+func GuessPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			if dir := path.Dir(importPath); dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, notIdentifier); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+func notIdentifier(r rune) bool {
+	return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Returns name of package from sources in dir, which satisfy build constraints, test packages are ignored.
+// Only package clause of the first matching file is read. Names are stored in cache, see SetCacheDir.
+func findPackageName(ov overlay, dir string) string {
+	infos, err := ov.readDir(dir)
+	if err != nil {
+		return ""
 	}
+	if currentCacheDir() == "" {
+		return findPackageNameIn(ov, dir, infos)
+	}
+	key, ok := packageNameCacheKey(ov, dir, infos)
+	if !ok {
+		return findPackageNameIn(ov, dir, infos)
+	}
+	if name, ok := loadCachedPackageName(key); ok {
+		return name
+	}
+	name := findPackageNameIn(ov, dir, infos)
+	storeCachedPackageName(key, name)
 	return name
 }
 
-func findPackageName(src, path string) string {
-	for _, gopath := range strings.Split(src, ":") {
-		pkgs, err := parser.ParseDir(token.NewFileSet(), filepath.Join(gopath, path), nil, parser.PackageClauseOnly)
+func findPackageNameIn(ov overlay, dir string, infos []os.FileInfo) string {
+	filter := sourceFilter(ov, dir)
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") || !filter(info) {
+			continue
+		}
+		filename := filepath.Join(dir, info.Name())
+		src, err := ov.readFile(filename)
 		if err != nil {
 			continue
 		}
-		for k := range pkgs {
-			return k
+		file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.PackageClauseOnly)
+		if err != nil {
+			continue
 		}
+		return file.Name.Name
 	}
 	return ""
 }
 
-func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
+func parseDeclaration(decl ast.Decl, file *types.File, r *Resolver, opt Option) error {
 	switch d := decl.(type) {
 	case *ast.GenDecl:
 		switch d.Tok {
@@ -179,13 +324,18 @@ func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
 				if !ok {
 					continue // if !ok then comment
 				}
-				alias := constructAliasName(spec)
+				alias, realName, guessed, kind := constructAliasName(spec, r)
 				imp := &types.Import{
 					Base: types.Base{
-						Name: alias,
-						Docs: parseCommentFromSources(opt, d.Doc, spec.Doc, spec.Comment),
+						Name:       alias,
+						Docs:       parseCommentFromSources(opt, d.Doc, spec.Doc, spec.Comment),
+						Comments:   parseSpecComments(opt, d, spec.Doc, spec.Comment),
+						Directives: parseSpecDirectives(d, spec.Doc, spec.Comment),
 					},
-					Package: strings.Trim(spec.Path.Value, `"`),
+					Package:  strings.Trim(spec.Path.Value, `"`),
+					Kind:     kind,
+					RealName: realName,
+					Guessed:  guessed,
 				}
 
 				imports = append(imports, imp)
@@ -221,11 +371,18 @@ func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
 					if err != nil {
 						return err
 					}
+					typeParams, err := parseParams(typeSpec.TypeParams, file, opt)
+					if err != nil {
+						return fmt.Errorf("%s: can't parse type params: %v", typeSpec.Name.Name, err)
+					}
 					file.Interfaces = append(file.Interfaces, types.Interface{
 						Base: types.Base{
-							Name: typeSpec.Name.Name,
-							Docs: parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
+							Name:       typeSpec.Name.Name,
+							Docs:       parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
+							Comments:   parseSpecComments(opt, d, typeSpec.Doc, typeSpec.Comment),
+							Directives: parseSpecDirectives(d, typeSpec.Doc, typeSpec.Comment),
 						},
+						TypeParams: typeParams,
 						Methods:    methods,
 						Interfaces: embedded,
 					})
@@ -237,12 +394,19 @@ func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
 					if err != nil {
 						return fmt.Errorf("%s: can't parse struct fields: %v", typeSpec.Name.Name, err)
 					}
+					typeParams, err := parseParams(typeSpec.TypeParams, file, opt)
+					if err != nil {
+						return fmt.Errorf("%s: can't parse type params: %v", typeSpec.Name.Name, err)
+					}
 					file.Structures = append(file.Structures, types.Struct{
 						Base: types.Base{
-							Name: typeSpec.Name.Name,
-							Docs: parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
+							Name:       typeSpec.Name.Name,
+							Docs:       parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
+							Comments:   parseSpecComments(opt, d, typeSpec.Doc, typeSpec.Comment),
+							Directives: parseSpecDirectives(d, typeSpec.Doc, typeSpec.Comment),
 						},
-						Fields: strFields,
+						TypeParams: typeParams,
+						Fields:     strFields,
 					})
 				default:
 					if opt.check(IgnoreTypes) {
@@ -252,10 +416,16 @@ func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
 					if err != nil {
 						return fmt.Errorf("%s: can't parse type: %v", typeSpec.Name.Name, err)
 					}
+					typeParams, err := parseParams(typeSpec.TypeParams, file, opt)
+					if err != nil {
+						return fmt.Errorf("%s: can't parse type params: %v", typeSpec.Name.Name, err)
+					}
 					file.Types = append(file.Types, types.FileType{Base: types.Base{
-						Name: typeSpec.Name.Name,
-						Docs: parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
-					}, Type: newType})
+						Name:       typeSpec.Name.Name,
+						Docs:       parseCommentFromSources(opt, d.Doc, typeSpec.Doc, typeSpec.Comment),
+						Comments:   parseSpecComments(opt, d, typeSpec.Doc, typeSpec.Comment),
+						Directives: parseSpecDirectives(d, typeSpec.Doc, typeSpec.Comment),
+					}, TypeParams: typeParams, Type: newType})
 				}
 			}
 		}
@@ -265,8 +435,10 @@ func parseDeclaration(decl ast.Decl, file *types.File, opt Option) error {
 		}
 		fn := types.Function{
 			Base: types.Base{
-				Name: d.Name.Name,
-				Docs: parseComments(d.Doc, opt),
+				Name:       d.Name.Name,
+				Docs:       parseComments(d.Doc, opt),
+				Comments:   parseCommentsModel(opt, nil, d.Doc, nil),
+				Directives: parseDirectives(d.Doc),
 			},
 		}
 		err := parseFuncParamsAndResults(d.Type, &fn, file, opt)
@@ -307,7 +479,10 @@ func parseReceiver(list *ast.FieldList, file *types.File, opt Option) (*types.Va
 }
 
 func parseVariables(decl *ast.GenDecl, file *types.File, opt Option) (vars []types.Variable, err error) {
-	iotaMark := false
+	var (
+		iotaMark bool
+		specType types.Type // type of previous spec, constants without type and values repeat it
+	)
 	for i := range decl.Specs {
 		spec := decl.Specs[i].(*ast.ValueSpec)
 		if len(spec.Values) > 0 && len(spec.Values) != len(spec.Names) {
@@ -316,8 +491,10 @@ func parseVariables(decl *ast.GenDecl, file *types.File, opt Option) (vars []typ
 		for i, name := range spec.Names {
 			variable := types.Variable{
 				Base: types.Base{
-					Name: name.Name,
-					Docs: parseCommentFromSources(opt, decl.Doc, spec.Doc, spec.Comment),
+					Name:       name.Name,
+					Docs:       parseCommentFromSources(opt, decl.Doc, spec.Doc, spec.Comment),
+					Comments:   parseSpecComments(opt, decl, spec.Doc, spec.Comment),
+					Directives: parseSpecDirectives(decl, spec.Doc, spec.Comment),
 				},
 			}
 			var (
@@ -336,6 +513,8 @@ func parseVariables(decl *ast.GenDecl, file *types.File, opt Option) (vars []typ
 				if err != nil {
 					return nil, fmt.Errorf("can't parse type: %v", err)
 				}
+			} else if decl.Tok == token.CONST && len(spec.Values) == 0 && specType != nil {
+				valType = specType
 			} else {
 				return nil, fmt.Errorf("can't parse type: %d:%d", spec.Pos(), spec.End())
 			}
@@ -343,6 +522,9 @@ func parseVariables(decl *ast.GenDecl, file *types.File, opt Option) (vars []typ
 			variable.Type = valType
 			vars = append(vars, variable)
 		}
+		if len(vars) > 0 {
+			specType = vars[len(vars)-1].Type
+		}
 	}
 	return
 }
@@ -378,13 +560,15 @@ func parseByType(spec interface{}, file *types.File, opt Option) (tt types.Type,
 		}
 		return types.TPointer{Next: next, NumberOfPointers: 1}, iotaMark, nil
 	case *ast.ArrayType:
-		l := parseArrayLen(t)
+		l, lenExpr := parseArrayLen(t)
 		next, iotaMark, err := parseByType(t.Elt, file, opt)
 		if err != nil {
 			return nil, false, err
 		}
 		switch l {
-		case -3, -2:
+		case -3:
+			return types.TArray{Next: next, LenExpr: lenExpr}, iotaMark, nil
+		case -2:
 			return types.TArray{Next: next, IsSlice: true}, iotaMark, nil
 		case -1:
 			return types.TArray{Next: next, IsEllipsis: true}, iotaMark, nil
@@ -427,6 +611,16 @@ func parseByType(spec interface{}, file *types.File, opt Option) (tt types.Type,
 		return types.TChan{Next: next, Direction: int(t.Dir)}, iotaMark, nil
 	case *ast.ParenExpr:
 		return parseByType(t.X, file, opt)
+	case *ast.IndexExpr:
+		return parseGenericType(t.X, []ast.Expr{t.Index}, file, opt)
+	case *ast.IndexListExpr:
+		return parseGenericType(t.X, t.Indices, file, opt)
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		terms, err := parseUnionTerms(t.(ast.Expr), file, opt)
+		if err != nil {
+			return nil, false, err
+		}
+		return types.TUnion{Terms: terms}, false, nil
 	case *ast.BadExpr:
 		return nil, false, fmt.Errorf("bad expression")
 	case *ast.FuncType:
@@ -445,21 +639,84 @@ func parseByType(spec interface{}, file *types.File, opt Option) (tt types.Type,
 	}
 }
 
-func parseArrayLen(t *ast.ArrayType) int {
-	if t == nil {
-		return -2
+// Parses instantiated generic type, like `List[int]` or `pkg.Map[K, V]`.
+func parseGenericType(x ast.Expr, indices []ast.Expr, file *types.File, opt Option) (types.Type, bool, error) {
+	base, _, err := parseByType(x, file, opt)
+	if err != nil {
+		return nil, false, err
+	}
+	args := make([]types.Type, len(indices))
+	for i := range indices {
+		args[i], _, err = parseByType(indices[i], file, opt)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	switch b := base.(type) {
+	case types.TName:
+		b.TypeArgs = args
+		return b, false, nil
+	case types.TImport:
+		if name, ok := b.Next.(types.TName); ok {
+			name.TypeArgs = args
+			b.Next = name
+			return b, false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("unexpected generic type %s", base)
+}
+
+// Parses terms of type union in constraints: `~int | ~string | fmt.Stringer`.
+func parseUnionTerms(expr ast.Expr, file *types.File, opt Option) ([]types.TTerm, error) {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op != token.OR {
+			return nil, fmt.Errorf("%v: binary %s", ErrUnexpectedSpec, e.Op)
+		}
+		x, err := parseUnionTerms(e.X, file, opt)
+		if err != nil {
+			return nil, err
+		}
+		y, err := parseUnionTerms(e.Y, file, opt)
+		if err != nil {
+			return nil, err
+		}
+		return append(x, y...), nil
+	case *ast.UnaryExpr:
+		if e.Op != token.TILDE {
+			return nil, fmt.Errorf("%v: unary %s", ErrUnexpectedSpec, e.Op)
+		}
+		t, _, err := parseByType(e.X, file, opt)
+		if err != nil {
+			return nil, err
+		}
+		return []types.TTerm{{Tilde: true, Type: t}}, nil
+	default:
+		t, _, err := parseByType(e, file, opt)
+		if err != nil {
+			return nil, err
+		}
+		return []types.TTerm{{Type: t}}, nil
+	}
+}
+
+// Returns length of array: -2 for slices, -1 for `[...]T`, -3 for constant expressions, which can not be evaluated
+// without type checking, they are returned as the second value.
+func parseArrayLen(t *ast.ArrayType) (int, string) {
+	if t == nil || t.Len == nil {
+		return -2, ""
 	}
 	switch l := t.Len.(type) {
 	case *ast.Ellipsis:
-		return -1
+		return -1, ""
 	case *ast.BasicLit:
 		if l.Kind == token.INT {
-			x, _ := strconv.Atoi(l.Value)
-			return x
+			if x, err := strconv.ParseInt(l.Value, 0, 64); err == nil {
+				return int(x), ""
+			}
 		}
-		return 0
 	}
-	return -3
+	return -3, gotypes.ExprString(t.Len)
 }
 
 // Fill provided types.Type for cases, when variable's value is provided.
@@ -510,16 +767,18 @@ func parseInterfaceMethods(ifaceType *ast.InterfaceType, file *types.File, opt O
 					return nil, nil, err
 				}
 				fns = append(fns, fn)
-			case *ast.Ident:
-				// Embedded interfaces
+			default:
+				// Embedded interfaces and type unions
 				iface, _, err := parseByType(method.Type, file, opt)
 				if err != nil {
 					return nil, nil, err
 				}
 				v := types.Variable{
 					Base: types.Base{
-						Name: "", // Because we embed interface.
-						Docs: parseCommentFromSources(opt, method.Doc, method.Comment),
+						Name:       "", // Because we embed interface.
+						Docs:       parseCommentFromSources(opt, method.Doc, method.Comment),
+						Comments:   parseCommentsModel(opt, nil, method.Doc, method.Comment),
+						Directives: parseDirectives(method.Doc, method.Comment),
 					},
 					Type: iface,
 				}
@@ -537,7 +796,9 @@ func parseFunctionDeclaration(funcField *ast.Field, file *types.File, opt Option
 		return nil, fmt.Errorf("%s: %v", funcField.Names[0].Name, err)
 	}
 	fn.Base.Name = funcField.Names[0].Name
-	fn.Base.Docs = parseComments(funcField.Doc, opt)
+	fn.Base.Docs = parseCommentFromSources(opt, funcField.Doc, funcField.Comment)
+	fn.Base.Comments = parseCommentsModel(opt, nil, funcField.Doc, funcField.Comment)
+	fn.Base.Directives = parseDirectives(funcField.Doc, funcField.Comment)
 	return fn, nil
 }
 
@@ -551,6 +812,11 @@ func parseFunction(funcType *ast.FuncType, file *types.File, opt Option) (*types
 }
 
 func parseFuncParamsAndResults(funcType *ast.FuncType, fn *types.Function, file *types.File, opt Option) error {
+	typeParams, err := parseParams(funcType.TypeParams, file, opt)
+	if err != nil {
+		return fmt.Errorf("can't parse type params: %v", err)
+	}
+	fn.TypeParams = typeParams
 	args, err := parseParams(funcType.Params, file, opt)
 	if err != nil {
 		return fmt.Errorf("can't parse args: %v", err)
@@ -579,10 +845,14 @@ func parseParams(fields *ast.FieldList, file *types.File, opt Option) ([]types.V
 			return nil, fmt.Errorf("wrong type of %s: %v", strings.Join(namesOfIdents(field.Names), ","), err)
 		}
 		docs := parseCommentFromSources(opt, field.Doc, field.Comment)
+		comments := parseCommentsModel(opt, nil, field.Doc, field.Comment)
+		directives := parseDirectives(field.Doc, field.Comment)
 		if len(field.Names) == 0 {
 			vars = append(vars, types.Variable{
 				Base: types.Base{
-					Docs: docs,
+					Docs:       docs,
+					Comments:   comments,
+					Directives: directives,
 				},
 				Type: t,
 			})
@@ -590,8 +860,10 @@ func parseParams(fields *ast.FieldList, file *types.File, opt Option) ([]types.V
 			for _, name := range field.Names {
 				vars = append(vars, types.Variable{
 					Base: types.Base{
-						Name: name.Name,
-						Docs: docs,
+						Name:       name.Name,
+						Docs:       docs,
+						Comments:   comments,
+						Directives: directives,
 					},
 					Type: t,
 				})
@@ -601,21 +873,13 @@ func parseParams(fields *ast.FieldList, file *types.File, opt Option) ([]types.V
 	return vars, nil
 }
 
-func parseTags(lit *ast.BasicLit) (tags map[string][]string, raw string) {
+// Parses tags of field. Tags before syntax error are kept, error is returned with its position.
+func parseTags(lit *ast.BasicLit) (tags map[string][]string, list []types.Tag, raw string, pos token.Pos, err error) {
 	if lit == nil {
 		return
 	}
-	raw = lit.Value
-	str := strings.Trim(lit.Value, "`")
-	t, err := structtag.Parse(str)
-	if err != nil {
-		return
-	}
-	tags = make(map[string][]string)
-	for _, tag := range t.Tags() {
-		tags[tag.Key] = append([]string{tag.Name}, tag.Options...)
-	}
-	return
+	list, pos, err = parseTagList(lit)
+	return tagsMap(list), list, lit.Value, pos, err
 }
 
 func parseStructFields(s *ast.StructType, file *types.File, opt Option) ([]types.StructField, error) {
@@ -624,31 +888,59 @@ func parseStructFields(s *ast.StructType, file *types.File, opt Option) ([]types
 		return nil, err
 	}
 	var strF []types.StructField
-	for i, f := range fields {
-		var tags *ast.BasicLit
-		// Fill tags, if Tag field exist in ast
-		if i < len(s.Fields.List) {
-			tags = s.Fields.List[i].Tag
-		}
-		parsedTags, rawTags := parseTags(tags)
-		strF = append(strF, types.StructField{
-			Variable: f,
-			Tags:     parsedTags,
-			RawTags:  rawTags,
-		})
+	// One ast field declares several variables, when it has several names, e.g. `A, B int`.
+	i := 0
+	for _, field := range s.Fields.List {
+		parsedTags, tagList, rawTags, pos, err := parseTags(field.Tag)
+		if err != nil {
+			file.Diagnostics = append(file.Diagnostics, types.Diagnostic{
+				Message: fmt.Sprintf("malformed tag of %s: %v", fieldName(field), err),
+				Pos:     pos,
+			})
+		}
+		var tagPos token.Pos
+		if field.Tag != nil {
+			tagPos = field.Tag.ValuePos
+		}
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for ; n > 0 && i < len(fields); n-- {
+			strF = append(strF, types.StructField{
+				Variable: fields[i],
+				Tags:     parsedTags,
+				TagList:  tagList,
+				RawTags:  rawTags,
+				TagPos:   tagPos,
+				TypeEnd:  field.Type.End(),
+			})
+			i++
+		}
 	}
 	return strF, nil
 }
 
+// Returns names of field or `embedded field`.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return "embedded field"
+	}
+	return "field " + strings.Join(namesOfIdents(field.Names), ", ")
+}
+
 func findImportByAlias(file *types.File, alias string) (*types.Import, error) {
+	if alias == "" {
+		return nil, fmt.Errorf("%v: empty name", ErrCouldNotResolvePackage)
+	}
 	for _, imp := range file.Imports {
-		if imp.Name == alias {
+		if imp.LocalName() == alias {
 			return imp, nil
 		}
 	}
-	// try to find by last segment of package path
+	// try to find by name of package
 	for _, imp := range file.Imports {
-		if alias == path.Base(imp.Package) {
+		if imp.LocalName() != "" && (alias == imp.RealName || alias == GuessPackageName(imp.Package)) {
 			return imp, nil
 		}
 	}
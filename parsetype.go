@@ -0,0 +1,121 @@
+package astra
+
+import (
+	"go/ast"
+	astparser "go/parser"
+	"go/scanner"
+	"go/token"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// ParseType parses type expression, like `map[string]*pkg.Thing` or `func(context.Context) error`.
+// Qualifiers of imported types are resolved against imports in the same way as in parsed files.
+// Array lengths may be constant expressions, like `[N]byte` or `[2 * pkg.Size]byte`, they are kept in TArray.LenExpr.
+// Returned error is a scanner.ErrorList, positions in it are relative to expr.
+func ParseType(expr string, imports []*types.Import, options ...Option) (types.Type, error) {
+	opt := concatOptions(options)
+	fset := token.NewFileSet()
+	x, err := astparser.ParseExprFrom(fset, "", expr, 0)
+	if err != nil {
+		return nil, err
+	}
+	file := &types.File{Imports: imports}
+	var (
+		errs  scanner.ErrorList
+		check func(ast.Node) bool
+	)
+	check = func(node ast.Node) bool {
+		if len(errs) > 0 {
+			return false
+		}
+		msg := checkTypeExprNode(node, file, opt)
+		if msg != "" {
+			errs.Add(fset.Position(node.Pos()), msg)
+			return false
+		}
+		if array, ok := node.(*ast.ArrayType); ok {
+			// length is already checked
+			ast.Inspect(array.Elt, check)
+			return false
+		}
+		return true
+	}
+	ast.Inspect(x, check)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	t, _, err := parseByType(x, file, opt)
+	if err != nil {
+		errs.Add(fset.Position(x.Pos()), err.Error())
+		return nil, errs
+	}
+	return t, nil
+}
+
+// Returns description of the problem, when node can not be a part of type expression.
+func checkTypeExprNode(node ast.Node, file *types.File, opt Option) string {
+	switch n := node.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := n.X.(*ast.Ident)
+		if !ok {
+			return "qualifier of " + n.Sel.Name + " is not a package name"
+		}
+		if opt.check(AllowAnyImportAliases) {
+			return ""
+		}
+		if _, err := findImportByAlias(file, pkg.Name); err != nil {
+			return err.Error()
+		}
+	case *ast.ArrayType:
+		if n.Len == nil {
+			return ""
+		}
+		switch l := n.Len.(type) {
+		case *ast.Ellipsis:
+		case *ast.BasicLit:
+			if l.Kind != token.INT {
+				return "array length must be an integer"
+			}
+		default:
+			return checkConstExpr(l, file, opt)
+		}
+	case *ast.BinaryExpr:
+		if n.Op != token.OR {
+			return "unexpected operator " + n.Op.String() + " in type"
+		}
+	case *ast.UnaryExpr:
+		if n.Op != token.TILDE {
+			return "unexpected operator " + n.Op.String() + " in type"
+		}
+	case *ast.BasicLit:
+		return "unexpected literal " + n.Value + " in type"
+	case *ast.CallExpr, *ast.CompositeLit, *ast.FuncLit, *ast.KeyValueExpr, *ast.SliceExpr, *ast.TypeAssertExpr, *ast.BadExpr:
+		return "expression is not a type"
+	}
+	return ""
+}
+
+// Returns description of the problem, when expr can not be a constant expression of array length.
+func checkConstExpr(expr ast.Expr, file *types.File, opt Option) string {
+	var msg string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if msg != "" {
+			return false
+		}
+		switch n := node.(type) {
+		case nil, *ast.Ident, *ast.ParenExpr, *ast.BinaryExpr, *ast.UnaryExpr:
+		case *ast.BasicLit:
+			if n.Kind == token.STRING {
+				msg = "array length must be an integer constant"
+			}
+		case *ast.SelectorExpr:
+			msg = checkTypeExprNode(n, file, opt)
+			return false
+		default:
+			msg = "array length must be an integer constant"
+		}
+		return msg == ""
+	})
+	return msg
+}
@@ -0,0 +1,49 @@
+package astra
+
+import (
+	"testing"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestParseType(t *testing.T) {
+	imports := []*types.Import{
+		{Base: types.Base{Name: "context"}, Package: "context"},
+		{Base: types.Base{Name: "pkg"}, Package: "example.com/pkg"},
+	}
+	for expr, expected := range map[string]string{
+		"map[string]*pkg.Thing":           "map[string]*pkg.Thing",
+		"func(context.Context) error":     "func ( context.Context) ( error)",
+		"[]**[4]chan<- pkg.List[int]":     "[]**[4]chan<- pkg.List[int]",
+		"interface{ Do(...string) }":      "type  interface {\n\tDo( ...string) ()\n}",
+		"(map[pkg.Key]struct{ A int })":   "map[pkg.Key] struct {\nA int ``\n}",
+		"pkg.Pair[string, chan struct{}]": "pkg.Pair[string, chan  struct {}]",
+		"[N]int":                          "[N]int",
+		"[2 * (pkg.Size + 1)]byte":        "[2 * (pkg.Size + 1)]byte",
+	} {
+		tt, err := ParseType(expr, imports)
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		if tt.String() != expected {
+			t.Errorf("%s: has %q want %q", expr, tt.String(), expected)
+		}
+	}
+	for expr, expected := range map[string]string{
+		"map[string]":      "1:12: expected type, found newline",
+		"[]unknown.Thing":  "1:3: could not resolve package: unknown",
+		"[f(x)]int":        "1:1: array length must be an integer constant",
+		"[unknown.N]int":   "1:1: could not resolve package: unknown",
+		`["4"]int`:         "1:1: array length must be an integer",
+		"map[string]f(x)":  "1:1: expression is not a type",
+		"*pkg.Thing{A: 1}": "1:2: expression is not a type",
+		"a.b.C":            "1:1: qualifier of C is not a package name",
+		"[4]int | 1":       "1:10: unexpected literal 1 in type",
+	} {
+		_, err := ParseType(expr, imports)
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: has error %v want %s", expr, err, expected)
+		}
+	}
+}
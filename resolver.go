@@ -0,0 +1,356 @@
+package astra
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	astparser "go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+var (
+	ErrDeclNotFound    = errors.New("declaration not found")
+	ErrNotImportedType = errors.New("type is not imported")
+	ErrImportCycle     = errors.New("import cycle not allowed")
+)
+
+// Resolver lazily loads sources of imported packages and finds declarations in them.
+// Loaded packages are cached, so one Resolver should be shared across the whole load.
+// Resolver never downloads anything: packages are searched in the main module, vendor directories,
+// module cache, GOROOT and GOPATH.
+type Resolver struct {
+	dir     string
+	options []Option
+	overlay overlay
+	fset    *token.FileSet // shared by all parsed and type-checked packages
+
+	mx       sync.Mutex
+	mod      *goMod
+	modErr   error
+	modOnce  sync.Once
+	work     *goWork
+	workErr  error
+	workOnce sync.Once
+	packages map[string]*types.File
+	errs     map[string]error
+
+	checkMx  sync.Mutex
+	importer *sourceImporter
+
+	namesMx sync.Mutex
+	names   map[string]packageNameInfo // names of packages, which are found with overlay
+}
+
+// NewResolver returns Resolver for imports of the package in dir.
+// Options are used when parsing imported packages.
+func NewResolver(dir string, options ...Option) (*Resolver, error) {
+	return newResolver(dir, nil, options...)
+}
+
+// Returns Resolver, which reads files from disk and overlay.
+func newResolver(dir string, ov overlay, options ...Option) (*Resolver, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("can not filepath.Abs: %v", err)
+	}
+	return &Resolver{
+		dir:      abs,
+		options:  options,
+		overlay:  ov,
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*types.File),
+		errs:     make(map[string]error),
+		names:    make(map[string]packageNameInfo),
+	}, nil
+}
+
+// Resolve returns declaration, to which t refers.
+func (r *Resolver) Resolve(t types.TImport) (types.Decl, error) {
+	if t.Import == nil {
+		return nil, ErrNotImportedType
+	}
+	name := types.TypeName(t.Next)
+	if name == nil {
+		return nil, fmt.Errorf("%v: %s", ErrDeclNotFound, t.String())
+	}
+	pkg, err := r.Package(t.Import.Package)
+	if err != nil {
+		return nil, err
+	}
+	decl := pkg.FindDecl(*name)
+	if decl == nil {
+		return nil, fmt.Errorf("%v: %s.%s", ErrDeclNotFound, t.Import.Package, *name)
+	}
+	return decl, nil
+}
+
+// ResolveType finds first imported type in t, e.g. in pointer or slice, and resolves it.
+func (r *Resolver) ResolveType(t types.Type) (types.Decl, error) {
+	for tt := t; tt != nil; {
+		if imported, ok := tt.(types.TImport); ok {
+			return r.Resolve(imported)
+		}
+		next, ok := tt.(types.LinearType)
+		if !ok {
+			break
+		}
+		tt = next.NextType()
+	}
+	return nil, ErrNotImportedType
+}
+
+// ResolveDotImports qualifies type names of file, which are declared in dot-imported packages, with their imports.
+// Names of local declarations, builtin types and type parameters are not changed.
+func (r *Resolver) ResolveDotImports(file *types.File) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.resolveDotImportsLocked(file)
+}
+
+// Resolves dot imports of file, which are loaded from dir with overlay, when options have ResolveDotImports.
+func resolveDotImports(file *types.File, dir string, ov overlay, options ...Option) error {
+	if !concatOptions(options).check(ResolveDotImports) {
+		return nil
+	}
+	resolver, err := newResolver(dir, ov, options...)
+	if err != nil {
+		return err
+	}
+	return resolver.ResolveDotImports(file)
+}
+
+// Works like ResolveDotImports, r.mx should be held.
+func (r *Resolver) resolveDotImportsLocked(file *types.File) error {
+	type dotImport struct {
+		imp *types.Import
+		pkg *types.File
+	}
+	var dots []dotImport
+	for _, imp := range file.Imports {
+		if imp == nil || imp.Kind != types.ImportDot {
+			continue
+		}
+		pkg, err := r.packageLocked(imp.Package)
+		if err != nil {
+			return fmt.Errorf("can not resolve dot imports: %v", err)
+		}
+		dots = append(dots, dotImport{imp: imp, pkg: pkg})
+	}
+	if len(dots) == 0 {
+		return nil
+	}
+	typeParams := typeParamNames(file)
+	file.MapTypes(func(t types.Type) types.Type {
+		name, ok := t.(types.TName)
+		if !ok || types.IsBuiltinTypeString(name.TypeName) || typeParams[name.TypeName] || file.FindDecl(name.TypeName) != nil {
+			return t
+		}
+		for _, dot := range dots {
+			if dot.pkg.FindDecl(name.TypeName) != nil {
+				return types.TImport{Import: dot.imp, Next: name}
+			}
+		}
+		return t
+	})
+	return nil
+}
+
+// Returns names of all type parameters of declarations of file.
+func typeParamNames(file *types.File) map[string]bool {
+	names := make(map[string]bool)
+	add := func(params []types.Variable) {
+		for _, p := range params {
+			names[p.Name] = true
+		}
+	}
+	for _, s := range file.Structures {
+		add(s.TypeParams)
+	}
+	for _, i := range file.Interfaces {
+		add(i.TypeParams)
+	}
+	for _, t := range file.Types {
+		add(t.TypeParams)
+	}
+	for _, f := range file.Functions {
+		add(f.TypeParams)
+	}
+	return names
+}
+
+// Package returns merged file of all package sources by its import path.
+func (r *Resolver) Package(importPath string) (*types.File, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.packageLocked(importPath)
+}
+
+// Returns cached package or loads it, r.mx should be held.
+func (r *Resolver) packageLocked(importPath string) (*types.File, error) {
+	if f, ok := r.packages[importPath]; ok {
+		return f, nil
+	}
+	if err, ok := r.errs[importPath]; ok {
+		return nil, err
+	}
+	// Dot imports of loaded package are resolved while it is loaded, so package, which imports itself, gets this error.
+	r.errs[importPath] = ErrImportCycle
+	f, err := r.loadPackage(importPath)
+	delete(r.errs, importPath)
+	if err != nil {
+		err = fmt.Errorf("%s: %v", importPath, err)
+		r.errs[importPath] = err
+		return nil, err
+	}
+	r.packages[importPath] = f
+	return f, nil
+}
+
+func (r *Resolver) loadPackage(importPath string) (*types.File, error) {
+	dir, err := r.PackageDir(importPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.parsePackageDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if concatOptions(r.options).check(ResolveDotImports) {
+		err = r.resolveDotImportsLocked(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (r *Resolver) goMod() (*goMod, error) {
+	r.modOnce.Do(func() {
+		r.mod, r.modErr = findGoMod(r.overlay, r.dir)
+	})
+	return r.mod, r.modErr
+}
+
+func (r *Resolver) goWork() (*goWork, error) {
+	r.workOnce.Do(func() {
+		r.work, r.workErr = findGoWork(r.overlay, r.dir)
+	})
+	return r.work, r.workErr
+}
+
+// Returns key of cached name of package with importPath, which is found by r.
+func (r *Resolver) packageNameKey(importPath string) packageNameKey {
+	key := packageNameKey{module: r.dir, importPath: importPath}
+	if work, err := r.goWork(); err == nil && work != nil {
+		key.work = work.Dir
+	}
+	if mod, err := r.goMod(); err == nil && mod != nil {
+		key.module = mod.Dir
+	}
+	return key
+}
+
+// PackageDir returns directory with sources of package.
+// Packages are searched in GOROOT, modules of go.work workspace, the main module and its dependencies, vendor directories
+// and GOPATH, which is a fallback for projects without modules.
+func (r *Resolver) PackageDir(importPath string) (string, error) {
+	mod, err := r.goMod()
+	if err != nil {
+		return "", err
+	}
+	work, err := r.goWork()
+	if err != nil {
+		return "", err
+	}
+	if dir := filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)); isStdPackage(importPath) && r.overlay.isDir(dir) {
+		return dir, nil
+	}
+	if dir := filepath.Join(build.Default.GOROOT, "src", "vendor", filepath.FromSlash(importPath)); r.overlay.isDir(dir) {
+		return dir, nil
+	}
+	if work != nil {
+		if dir := work.packageDir(importPath); dir != "" && r.overlay.isDir(dir) {
+			return dir, nil
+		}
+	}
+	if mod != nil {
+		if dir := mod.packageDir(importPath); dir != "" && r.overlay.isDir(dir) {
+			return dir, nil
+		}
+	}
+	for dir := r.dir; ; {
+		vendored := filepath.Join(dir, "vendor", filepath.FromSlash(importPath))
+		if r.overlay.isDir(vendored) {
+			return vendored, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		dir := filepath.Join(gopath, "src", filepath.FromSlash(importPath))
+		if r.overlay.isDir(dir) {
+			return dir, nil
+		}
+	}
+	return "", ErrCouldNotResolvePackage
+}
+
+// Standard library packages do not have dot in the first path element.
+func isStdPackage(importPath string) bool {
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+// Parses all non-test .go files from directory, which satisfy build constraints, and merges them to one file.
+func (r *Resolver) parsePackageDir(dir string) (*types.File, error) {
+	fset := r.fset
+	pkgs, err := parseDir(diskFS{overlay: r.overlay}, fset, dir, sourceFilter(r.overlay, dir), astparser.ParseComments, concatOptions(r.options))
+	if err != nil {
+		return nil, fmt.Errorf("can not parse dir: %v", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("unexpected number of packages: expect 1, found %d", len(pkgs))
+	}
+	for _, pkg := range pkgs {
+		astFiles := packageFiles(pkg)
+		for _, file := range astFiles {
+			attachParamComments(fset, file)
+		}
+		f, err := parseMergedPackage(pkg, astFiles, r.nameResolver(dir), r.options...)
+		if err != nil {
+			return nil, err
+		}
+		f.FileSet = fset
+		if concatOptions(r.options).check(CheckTypes) {
+			err = r.checkAndAnnotate(f, fset, astFiles)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return f, nil
+	}
+	return nil, nil
+}
+
+// Returns files of package sorted by name.
+func packageFiles(pkg *ast.Package) []*ast.File {
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*ast.File, len(names))
+	for i := range names {
+		files[i] = pkg.Files[names[i]]
+	}
+	return files
+}
@@ -0,0 +1,202 @@
+package astra
+
+import (
+	"fmt"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// SliceOption adds optional dependencies to the slice of the file.
+type SliceOption uint
+
+const (
+	// Add methods of sliced structures and named types. Types from their receivers, arguments and results are sliced too.
+	SliceMethods SliceOption = 1 << iota
+	// Add constants of sliced named types, e.g. values of enums.
+	SliceEnums
+)
+
+// Slice returns new file with declarations, which are named in names, and all declarations of the file,
+// which are referenced by their types transitively: structures, interfaces and named types.
+// Declarations keep their order from the file, imports of the slice are reduced to required ones.
+// Types from dot imports are not distinguished from local types, resolve them with Resolver.ResolveDotImports or ResolveDotImports option before.
+// Declarations of returned file are copies, but their types and imports are shared with the file.
+func Slice(file *types.File, names []string, options ...SliceOption) (*types.File, error) {
+	var opt SliceOption
+	for i := range options {
+		opt |= options[i]
+	}
+	s := &slicer{
+		file:     file,
+		opt:      opt,
+		included: make(map[string]bool),
+		methods:  make(map[int]bool),
+	}
+	for _, name := range names {
+		decl := file.FindDecl(name)
+		if decl == nil {
+			return nil, fmt.Errorf("%v: %s", ErrDeclNotFound, name)
+		}
+		s.add(name, decl)
+	}
+	for len(s.queue) > 0 {
+		decl := s.queue[0]
+		s.queue = s.queue[1:]
+		s.addDependencies(decl)
+	}
+	return s.result()
+}
+
+type slicer struct {
+	file     *types.File
+	opt      SliceOption
+	included map[string]bool // names of included top-level declarations
+	methods  map[int]bool    // indexes of included methods of the file
+	queue    []types.Decl
+}
+
+func (s *slicer) add(name string, decl types.Decl) {
+	if s.included[name] {
+		return
+	}
+	s.included[name] = true
+	s.queue = append(s.queue, decl)
+	switch decl.(type) {
+	case *types.Struct, *types.Interface, *types.FileType:
+	default:
+		return
+	}
+	if s.opt&SliceMethods != 0 {
+		for i := range s.file.Methods {
+			if !s.methods[i] && receiverName(s.file.Methods[i]) == name {
+				s.methods[i] = true
+				s.queue = append(s.queue, &s.file.Methods[i])
+			}
+		}
+	}
+	if s.opt&SliceEnums != 0 {
+		for i := range s.file.Constants {
+			if t, ok := s.file.Constants[i].Type.(types.TName); ok && t.TypeName == name {
+				s.add(s.file.Constants[i].Name, &s.file.Constants[i])
+			}
+		}
+	}
+}
+
+// Adds local types, which are referenced by types of declaration.
+func (s *slicer) addDependencies(decl types.Decl) {
+	params := typeParamNamesOf(decl)
+	types.WalkDeclTypes(decl, func(t types.Type) {
+		name, ok := t.(types.TName)
+		if !ok || params[name.TypeName] || types.IsBuiltinTypeString(name.TypeName) {
+			return
+		}
+		switch dep := s.file.FindDecl(name.TypeName).(type) {
+		case *types.Struct, *types.Interface, *types.FileType:
+			s.add(name.TypeName, dep)
+		}
+	})
+}
+
+func (s *slicer) result() (*types.File, error) {
+	f := s.file
+	slice := &types.File{
+		Base:    types.Base{Name: f.Name},
+		License: f.License,
+		FileSet: f.FileSet,
+	}
+	var decls []types.Decl
+	for i := range f.Constants {
+		if s.included[f.Constants[i].Name] {
+			slice.Constants = append(slice.Constants, f.Constants[i])
+			decls = append(decls, f.Constants[i])
+		}
+	}
+	for i := range f.Vars {
+		if s.included[f.Vars[i].Name] {
+			slice.Vars = append(slice.Vars, f.Vars[i])
+			decls = append(decls, f.Vars[i])
+		}
+	}
+	for i := range f.Interfaces {
+		if s.included[f.Interfaces[i].Name] {
+			slice.Interfaces = append(slice.Interfaces, f.Interfaces[i])
+			decls = append(decls, f.Interfaces[i])
+		}
+	}
+	for i := range f.Structures {
+		if s.included[f.Structures[i].Name] {
+			structure := f.Structures[i]
+			structure.Methods = nil
+			slice.Structures = append(slice.Structures, structure)
+			decls = append(decls, structure)
+		}
+	}
+	for i := range f.Functions {
+		if s.included[f.Functions[i].Name] {
+			slice.Functions = append(slice.Functions, f.Functions[i])
+			decls = append(decls, f.Functions[i])
+		}
+	}
+	for i := range f.Methods {
+		if s.methods[i] {
+			slice.Methods = append(slice.Methods, f.Methods[i])
+			decls = append(decls, f.Methods[i])
+		}
+	}
+	for i := range f.Types {
+		if s.included[f.Types[i].Name] {
+			typee := f.Types[i]
+			typee.Methods = nil
+			slice.Types = append(slice.Types, typee)
+			decls = append(decls, typee)
+		}
+	}
+	slice.Imports = slice.RequiredImports(decls...)
+	if err := linkMethodsToStructs(slice); err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// Returns name of receiver's type without pointers and type arguments.
+func receiverName(m types.Method) string {
+	if name := types.TypeName(m.Receiver.Type); name != nil {
+		return *name
+	}
+	return ""
+}
+
+// Returns names of type parameters of declaration. For methods they are type arguments of receiver.
+func typeParamNamesOf(decl types.Decl) map[string]bool {
+	names := make(map[string]bool)
+	add := func(params []types.Variable) {
+		for _, p := range params {
+			names[p.Name] = true
+		}
+	}
+	switch d := decl.(type) {
+	case *types.Struct:
+		add(d.TypeParams)
+	case *types.Interface:
+		add(d.TypeParams)
+	case *types.FileType:
+		add(d.TypeParams)
+	case *types.Function:
+		add(d.TypeParams)
+	case *types.Method:
+		add(d.TypeParams)
+		t := d.Receiver.Type
+		if p, ok := t.(types.TPointer); ok {
+			t = p.Next
+		}
+		if name, ok := t.(types.TName); ok {
+			for _, arg := range name.TypeArgs {
+				if arg, ok := arg.(types.TName); ok {
+					names[arg.TypeName] = true
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,245 @@
+package astra
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/scanner"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// TagRewriter changes tags of struct fields, e.g. adds `json` tags in snake_case to all exported fields,
+// adds `omitempty` option or removes `xml` tags.
+type TagRewriter struct {
+	Add           []string            // Keys of tags, which are added to exported fields. Embedded fields are not changed.
+	Naming        func(string) string // Converts Go name of field to name in added tags, SnakeCase is used, when it is nil.
+	Overwrite     bool                // Names of existing tags with keys from Add are replaced too.
+	Remove        []string            // Keys of tags to remove.
+	AddOptions    map[string][]string // Options to add to tags with the key, e.g. `"json": {"omitempty"}`.
+	RemoveOptions map[string][]string // Options to remove from tags with the key.
+}
+
+// Tags returns new tags of field. Tags with the same key are changed together.
+// Fields, declared in one line with other fields, are passed with multiName, tags are not added to them,
+// because they share one tag literal.
+func (r TagRewriter) Tags(field types.StructField, multiName bool) []types.Tag {
+	var tags []types.Tag
+	for _, tag := range field.TagList {
+		if !containsString(r.Remove, tag.Key) {
+			tag.Options = append([]string(nil), tag.Options...)
+			tags = append(tags, tag)
+		}
+	}
+	if field.Name != "" && field.Name != "_" && isExportedName(field.Name) && !multiName {
+		naming := r.Naming
+		if naming == nil {
+			naming = SnakeCase
+		}
+		for _, key := range r.Add {
+			i := indexOfTag(tags, key)
+			switch {
+			case i < 0:
+				tags = append(tags, types.Tag{Key: key, Name: naming(field.Name)})
+			case r.Overwrite && tags[i].Value != "-":
+				tags[i].Name = naming(field.Name)
+			}
+		}
+	}
+	for i := range tags {
+		// `-` means, that field is skipped by encoders, options have no sense for it.
+		if tags[i].Value == "-" {
+			continue
+		}
+		for _, option := range r.AddOptions[tags[i].Key] {
+			if !tags[i].HasOption(option) {
+				tags[i].Options = append(tags[i].Options, option)
+			}
+		}
+		var options []string
+		for _, option := range tags[i].Options {
+			if option != "" && !containsString(r.RemoveOptions[tags[i].Key], option) {
+				options = append(options, option)
+			}
+		}
+		tags[i].Options = options
+		tags[i].Value = strings.Join(append([]string{tags[i].Name}, tags[i].Options...), ",")
+		if tags[i].Value == "-" {
+			// `-,` is a field with name `-`, trailing comma keeps it from being skipped.
+			tags[i].Value = "-,"
+		}
+	}
+	return tags
+}
+
+// FormatTags formats tags to literal of struct tag. It returns empty string for empty list.
+func FormatTags(tags []types.Tag) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(tags))
+	for i := range tags {
+		pairs[i] = tags[i].Key + ":" + strconv.Quote(tags[i].Value)
+	}
+	tag := strings.Join(pairs, " ")
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// Rewrite changes tags of all structures of file, which was parsed from src, and returns new source.
+// Only tag literals are changed, so comments and formatting are kept. When src is formatted by gofmt,
+// result is formatted too to align tags.
+// Fields with malformed tags are not changed and returned as scanner.ErrorList with new source.
+func (r TagRewriter) Rewrite(file *types.File, src []byte) ([]byte, error) {
+	if file.FileSet == nil {
+		return nil, fmt.Errorf("can not rewrite tags: file has no FileSet")
+	}
+	var (
+		edits []sourceEdit
+		errs  scanner.ErrorList
+	)
+	for _, fields := range groupFieldsByTag(structFieldsOf(file)) {
+		field := fields[0]
+		if field.RawTags != "" {
+			if _, _, err := parseTagList(&ast.BasicLit{Value: field.RawTags}); err != nil {
+				errs.Add(file.Position(field.TagPos), fmt.Sprintf("tag of field %s is not changed: %v", field.Name, err))
+				continue
+			}
+		}
+		tags := r.Tags(field, len(fields) > 1)
+		if equalTags(tags, field.TagList) {
+			continue
+		}
+		literal := FormatTags(tags)
+		typeEnd := file.Position(field.TypeEnd).Offset
+		switch {
+		case field.TagPos.IsValid() && literal == "":
+			// Spaces between type and tag are removed with tag.
+			start := file.Position(field.TagPos).Offset
+			edits = append(edits, sourceEdit{start: typeEnd, end: start + len(field.RawTags)})
+		case field.TagPos.IsValid():
+			start := file.Position(field.TagPos).Offset
+			edits = append(edits, sourceEdit{start: start, end: start + len(field.RawTags), text: literal})
+		case literal != "":
+			edits = append(edits, sourceEdit{start: typeEnd, end: typeEnd, text: " " + literal})
+		}
+	}
+	result, err := applyEdits(src, edits)
+	if err != nil {
+		return nil, err
+	}
+	if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+		if formatted, err := format.Source(result); err == nil {
+			result = formatted
+		}
+	}
+	if len(errs) > 0 {
+		errs.Sort()
+		return result, errs
+	}
+	return result, nil
+}
+
+// RewriteFile parses file and rewrites its tags. See TagRewriter.Rewrite.
+func (r TagRewriter) RewriteFile(filename string, options ...Option) ([]byte, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ParseSource(filename, src, options...)
+	if err != nil {
+		return nil, err
+	}
+	return r.Rewrite(file, src)
+}
+
+// Returns fields of all structures of file, including fields of anonymous structures in types of fields.
+func structFieldsOf(file *types.File) []types.StructField {
+	var fields []types.StructField
+	for _, s := range structsOf(file) {
+		fields = append(fields, s.Fields...)
+	}
+	return fields
+}
+
+// Groups fields, which are declared together, e.g. `A, B int`. They have the same position of type end.
+func groupFieldsByTag(fields []types.StructField) [][]types.StructField {
+	var (
+		groups [][]types.StructField
+		index  = make(map[int]int)
+	)
+	for _, field := range fields {
+		if !field.TypeEnd.IsValid() {
+			continue
+		}
+		i, ok := index[int(field.TypeEnd)]
+		if !ok {
+			i = len(groups)
+			index[int(field.TypeEnd)] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], field)
+	}
+	return groups
+}
+
+// Applies non-overlapping edits to source.
+func applyEdits(src []byte, edits []sourceEdit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	for _, e := range edits {
+		if e.start < last || e.end > len(src) || e.start > e.end {
+			return nil, fmt.Errorf("can not apply edit %d:%d to source of length %d", e.start, e.end, len(src))
+		}
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes(), nil
+}
+
+func equalTags(a, b []types.Tag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOfTag(tags []types.Tag, key string) int {
+	for i := range tags {
+		if tags[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package astra
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestTagRewriter(t *testing.T) {
+	src := "package tags\n\n" +
+		"type User struct {\n" +
+		"\tID        int    // identifier\n" +
+		"\tFirstName string `xml:\"first\" json:\"name\"`\n" +
+		"\tA, B      int\n" +
+		"\tSkipped   string `json:\"-\"`\n" +
+		"\tNested    struct {\n" +
+		"\t\tHTTPCode int `xml:\"code\"`\n" +
+		"\t}\n" +
+		"\tprivate int\n" +
+		"\tBroken  int `json:a`\n" +
+		"\tDash    int `json:\"-,\"`\n" +
+		"}\n"
+	expected := "package tags\n\n" +
+		"type User struct {\n" +
+		"\tID        int    `json:\"id,omitempty\"` // identifier\n" +
+		"\tFirstName string `json:\"name,omitempty\"`\n" +
+		"\tA, B      int\n" +
+		"\tSkipped   string `json:\"-\"`\n" +
+		"\tNested    struct {\n" +
+		"\t\tHTTPCode int `json:\"http_code,omitempty\"`\n" +
+		"\t} `json:\"nested,omitempty\"`\n" +
+		"\tprivate int\n" +
+		"\tBroken  int `json:a`\n" +
+		"\tDash    int `json:\"-,omitempty\"`\n" +
+		"}\n"
+	filename := filepath.Join(t.TempDir(), "tags.go")
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := TagRewriter{
+		Add:        []string{"json"},
+		Remove:     []string{"xml"},
+		AddOptions: map[string][]string{"json": {"omitempty"}},
+	}
+	result, err := r.RewriteFile(filename)
+	if err == nil || err.Error() != filename+":12:14: tag of field Broken is not changed: bad syntax for struct tag value" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if string(result) != expected {
+		t.Errorf("expected\n%s\nfound\n%s", expected, result)
+	}
+}
+
+func TestNaming(t *testing.T) {
+	for name, expected := range map[string][4]string{
+		"UserID":       {"user_id", "user-id", "userId", "UserId"},
+		"HTTPServer2":  {"http_server2", "http-server2", "httpServer2", "HttpServer2"},
+		"already_done": {"already_done", "already-done", "alreadyDone", "AlreadyDone"},
+		"X":            {"x", "x", "x", "X"},
+	} {
+		actual := [4]string{SnakeCase(name), KebabCase(name), CamelCase(name), PascalCase(name)}
+		if actual != expected {
+			t.Errorf("%s: expected %v, found %v", name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package astra
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// Checks of TagLinter.
+const (
+	CheckMalformedTag    = "malformed-tag"
+	CheckDuplicateKey    = "duplicate-key"
+	CheckDuplicateName   = "duplicate-name"
+	CheckUnexportedField = "unexported-field"
+	CheckNamingStyle     = "naming-style"
+)
+
+// Keys of tags, which names are checked by TagLinter by default.
+var DefaultLintKeys = []string{"json", "yaml", "db"}
+
+// Keys of tags of encoding packages, which ignore unexported fields.
+var EncodingTagKeys = []string{"json", "yaml", "xml", "toml", "db", "bson", "mapstructure"}
+
+// TagIssue is a problem of struct tag, found by TagLinter.
+type TagIssue struct {
+	Check    string         `json:"check"`
+	Message  string         `json:"message"`
+	Struct   string         `json:"struct,omitempty"`
+	Field    string         `json:"field,omitempty"`
+	Position token.Position `json:"position"`
+}
+
+// Returns issue in `file:line:col: message` format.
+func (i TagIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Position, i.Message, i.Check)
+}
+
+// TagLinter checks struct tags of all structures of file: malformed tags, duplicated keys,
+// duplicated names of fields within structure, including fields promoted from embedded structures,
+// tags of unexported fields and inconsistent naming styles of names in tags.
+// Promoted fields are reported too: encoders drop fields with the same names on the same depth of embedding
+// and ignore shadowed fields silently or fail, as yaml does for inlined structures.
+type TagLinter struct {
+	Keys     []string  // Keys of tags, which names are checked. DefaultLintKeys are used, when it is empty.
+	Resolver *Resolver // Loads embedded structures from other packages. When it is nil, they are not checked.
+}
+
+// Lint returns issues of all structures of file, sorted by positions.
+// Positions are resolved by f.FileSet.
+func (l TagLinter) Lint(file *types.File) []TagIssue {
+	var issues []TagIssue
+	for _, s := range structsOf(file) {
+		issues = append(issues, l.lintStruct(file, s)...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		x, y := issues[i].Position, issues[j].Position
+		if x.Filename != y.Filename {
+			return x.Filename < y.Filename
+		}
+		return x.Offset < y.Offset
+	})
+	return issues
+}
+
+func (l TagLinter) keys() []string {
+	if len(l.Keys) == 0 {
+		return DefaultLintKeys
+	}
+	return l.Keys
+}
+
+func (l TagLinter) lintStruct(file *types.File, s types.Struct) []TagIssue {
+	var issues []TagIssue
+	report := func(check string, field types.StructField, pos token.Pos, format string, args ...interface{}) {
+		if !pos.IsValid() {
+			pos = fieldPos(field)
+		}
+		issues = append(issues, TagIssue{
+			Check:    check,
+			Message:  fmt.Sprintf(format, args...),
+			Struct:   s.Name,
+			Field:    field.Name,
+			Position: file.Position(pos),
+		})
+	}
+	for _, field := range s.Fields {
+		if field.RawTags == "" {
+			continue
+		}
+		if _, pos, err := parseTagList(&ast.BasicLit{ValuePos: field.TagPos, Value: field.RawTags}); err != nil {
+			report(CheckMalformedTag, field, pos, "malformed tag of field %s: %v", displayFieldName(field), err)
+		}
+		seen := make(map[string]bool)
+		for _, tag := range field.TagList {
+			if seen[tag.Key] {
+				report(CheckDuplicateKey, field, tag.Pos, "duplicate key %s in tag of field %s", tag.Key, displayFieldName(field))
+			}
+			seen[tag.Key] = true
+			if field.Name != "" && !isExportedName(field.Name) && containsString(EncodingTagKeys, tag.Key) {
+				report(CheckUnexportedField, field, tag.Pos, "tag %s of unexported field %s is ignored", tag.Key, field.Name)
+			}
+		}
+	}
+	for _, key := range l.keys() {
+		names := l.encodedNames(file, s, key)
+		// Shallower field is the first one, because it shadows other fields with the same name.
+		sort.SliceStable(names, func(i, j int) bool { return names[i].depth < names[j].depth })
+		used := make(map[string]encodedName)
+		for _, name := range names {
+			if first, ok := used[name.name]; ok {
+				report(CheckDuplicateName, name.field, name.pos, "duplicate %s name %q of %s, it is also used by %s",
+					key, name.name, name.path, first.path)
+				continue
+			}
+			used[name.name] = name
+		}
+		issues = append(issues, l.lintNamingStyle(file, s, key)...)
+	}
+	return issues
+}
+
+// Name of field in encoded data.
+type encodedName struct {
+	name  string
+	path  string // Path to field through embedded structures.
+	depth int
+	field types.StructField // Field of linted structure, which contains named field.
+	pos   token.Pos
+}
+
+// Returns names of all fields of structure for tag key, including promoted fields.
+// Embedded structures are flattened as encoding/json does it, yaml flattens only structures with `inline` option.
+func (l TagLinter) encodedNames(file *types.File, s types.Struct, key string) []encodedName {
+	var (
+		names   []encodedName
+		visited = make(map[string]bool)
+		walk    func(file *types.File, s types.Struct, structKey, path string, depth int, top *types.StructField)
+	)
+	walk = func(file *types.File, s types.Struct, structKey, path string, depth int, top *types.StructField) {
+		if visited[structKey] {
+			return
+		}
+		visited[structKey] = true
+		defer delete(visited, structKey)
+		for _, field := range s.Fields {
+			owner := field
+			if top != nil {
+				owner = *top
+			}
+			embedded := field.Name == ""
+			goName := field.Name
+			if embedded {
+				goName = embeddedName(field.Type)
+			}
+			tag := field.Tag(key)
+			if tag != nil && tag.Name == "-" && len(tag.Options) == 0 {
+				continue
+			}
+			if embedded && (tag == nil || tag.Name == "") && flattensEmbedded(key, tag) {
+				t, err := underlyingOf(file, derefOnce(field.Type), l.Resolver)
+				if err == nil && t.s != nil {
+					walk(t.file, *t.s, t.key, path+goName+".", depth+1, &owner)
+					continue
+				}
+			}
+			if !embedded && !isExportedName(goName) {
+				continue
+			}
+			name := defaultEncodedName(key, goName)
+			if tag != nil && tag.Name != "" {
+				name = tag.Name
+			}
+			pos := token.NoPos
+			if top == nil {
+				pos = fieldPos(field)
+				if tag != nil {
+					pos = tag.Pos
+				}
+			} else {
+				pos = fieldPos(owner)
+			}
+			names = append(names, encodedName{name: name, path: path + goName, depth: depth, field: owner, pos: pos})
+		}
+	}
+	walk(file, s, "."+s.Name, "", 0, nil)
+	return names
+}
+
+// Checks, that encoder of key flattens embedded structure without name in tag.
+func flattensEmbedded(key string, tag *types.Tag) bool {
+	if key == "yaml" {
+		return tag != nil && tag.HasOption("inline")
+	}
+	return true
+}
+
+// Returns name of field, which encoder uses, when tag has no name.
+func defaultEncodedName(key, goName string) string {
+	switch key {
+	case "yaml", "db":
+		return strings.ToLower(goName)
+	}
+	return goName
+}
+
+// Naming styles of names in tags.
+const (
+	styleLower  = "lower"
+	styleSnake  = "snake_case"
+	styleKebab  = "kebab-case"
+	styleCamel  = "camelCase"
+	stylePascal = "PascalCase"
+	styleMixed  = "mixed"
+)
+
+// Returns naming style of name. Names from one lower case word match snake, kebab and camel cases.
+func namingStyle(name string) string {
+	var hasUpper, hasUnderscore, hasDash bool
+	for _, r := range name {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case r == '_':
+			hasUnderscore = true
+		case r == '-':
+			hasDash = true
+		}
+	}
+	first := []rune(name)[0]
+	switch {
+	case hasUnderscore && hasDash:
+		return styleMixed
+	case !hasUpper && hasUnderscore:
+		return styleSnake
+	case !hasUpper && hasDash:
+		return styleKebab
+	case !hasUpper:
+		return styleLower
+	case hasUnderscore || hasDash:
+		return styleMixed
+	case unicode.IsUpper(first):
+		return stylePascal
+	default:
+		return styleCamel
+	}
+}
+
+// Reports names in tags, which do not match the most used naming style of structure.
+func (l TagLinter) lintNamingStyle(file *types.File, s types.Struct, key string) []TagIssue {
+	type named struct {
+		field types.StructField
+		tag   types.Tag
+		style string
+	}
+	var (
+		names  []named
+		counts = make(map[string]int)
+		order  []string
+	)
+	for _, field := range s.Fields {
+		tag := field.Tag(key)
+		if tag == nil || tag.Name == "" || tag.Name == "-" {
+			continue
+		}
+		style := namingStyle(tag.Name)
+		names = append(names, named{field: field, tag: *tag, style: style})
+		if style == styleLower {
+			continue
+		}
+		if counts[style] == 0 {
+			order = append(order, style)
+		}
+		counts[style]++
+	}
+	dominant := ""
+	for _, style := range order {
+		if counts[style] > counts[dominant] {
+			dominant = style
+		}
+	}
+	if dominant == "" {
+		return nil
+	}
+	var issues []TagIssue
+	for _, n := range names {
+		if n.style == dominant || n.style == styleLower && dominant != stylePascal && dominant != styleMixed {
+			continue
+		}
+		issues = append(issues, TagIssue{
+			Check:    CheckNamingStyle,
+			Message:  fmt.Sprintf("%s name %q of field %s is not in %s, like other names of %s", key, n.tag.Name, n.field.Name, dominant, s.Name),
+			Struct:   s.Name,
+			Field:    n.field.Name,
+			Position: file.Position(n.tag.Pos),
+		})
+	}
+	return issues
+}
+
+// Returns all structures of file, including anonymous structures in types of fields.
+func structsOf(file *types.File) []types.Struct {
+	var (
+		structs []types.Struct
+		walk    func(s types.Struct)
+	)
+	walk = func(s types.Struct) {
+		structs = append(structs, s)
+		for _, field := range s.Fields {
+			for t := field.Type; t != nil; {
+				if nested, ok := t.(types.Struct); ok {
+					if nested.Name == "" {
+						nested.Name = s.Name + "." + displayFieldName(field)
+					}
+					walk(nested)
+				}
+				next, ok := t.(types.LinearType)
+				if !ok {
+					break
+				}
+				t = next.NextType()
+			}
+		}
+	}
+	for _, s := range file.Structures {
+		walk(s)
+	}
+	return structs
+}
+
+// Returns position of tag or end of type of field.
+func fieldPos(field types.StructField) token.Pos {
+	if field.TagPos.IsValid() {
+		return field.TagPos
+	}
+	return field.TypeEnd
+}
+
+func displayFieldName(field types.StructField) string {
+	if field.Name != "" {
+		return field.Name
+	}
+	return embeddedName(field.Type)
+}
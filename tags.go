@@ -0,0 +1,106 @@
+package astra
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+var (
+	errTagKeySyntax   = errors.New("bad syntax for struct tag key")
+	errTagPairSyntax  = errors.New("bad syntax for struct tag pair")
+	errTagValueSyntax = errors.New("bad syntax for struct tag value")
+)
+
+// Parses struct tag literal to tags in order of the source. Grammar is the same as reflect.StructTag uses.
+// On syntax error tags before it are returned with position of the error.
+func parseTagList(lit *ast.BasicLit) ([]types.Tag, token.Pos, error) {
+	if lit == nil {
+		return nil, token.NoPos, nil
+	}
+	tag, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		// Literals, which were constructed manually, may be not quoted.
+		tag = strings.Trim(lit.Value, "`")
+	}
+	// Offsets inside of interpreted string are not the same as in source, so only raw strings have exact positions.
+	posOf := func(offset int) token.Pos {
+		if !lit.ValuePos.IsValid() {
+			return token.NoPos
+		}
+		if strings.HasPrefix(lit.Value, "`") {
+			return lit.ValuePos + token.Pos(1+offset)
+		}
+		return lit.ValuePos
+	}
+	var (
+		tags   []types.Tag
+		offset int
+	)
+	for {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag, offset = tag[i:], offset+i
+		if tag == "" {
+			return tags, token.NoPos, nil
+		}
+		// Key is a sequence of non-control characters except space, quote and colon.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return tags, posOf(offset), errTagKeySyntax
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return tags, posOf(offset), errTagPairSyntax
+		}
+		if tag[i+1] != '"' {
+			return tags, posOf(offset + i + 1), errTagValueSyntax
+		}
+		t := types.Tag{Key: tag[:i], Pos: posOf(offset)}
+		valueOffset := offset + i + 1
+		tag = tag[i+1:]
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return tags, posOf(valueOffset), errTagValueSyntax
+		}
+		t.Value, err = strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return tags, posOf(valueOffset), errTagValueSyntax
+		}
+		parts := strings.Split(t.Value, ",")
+		t.Name = parts[0]
+		if len(parts) > 1 {
+			t.Options = parts[1:]
+		}
+		tags = append(tags, t)
+		tag, offset = tag[i+1:], valueOffset+i+1
+	}
+}
+
+// Converts tags to map from key to name and options. When key is duplicated, the first tag wins, as in reflect.StructTag.
+func tagsMap(list []types.Tag) map[string][]string {
+	if len(list) == 0 {
+		return nil
+	}
+	tags := make(map[string][]string, len(list))
+	for _, t := range list {
+		if _, ok := tags[t.Key]; !ok {
+			tags[t.Key] = append([]string{t.Name}, t.Options...)
+		}
+	}
+	return tags
+}
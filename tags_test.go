@@ -0,0 +1,63 @@
+package astra
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestParseTagList(t *testing.T) {
+	for _, tt := range []struct {
+		Lit    string
+		Tags   []types.Tag
+		Offset int
+		Err    error
+	}{
+		{
+			Lit: "`json:\"a,omitempty\"  xml:\"\"`",
+			Tags: []types.Tag{
+				{Key: "json", Name: "a", Options: []string{"omitempty"}, Value: "a,omitempty", Pos: 2},
+				{Key: "xml", Pos: 22},
+			},
+		},
+		{
+			Lit:  `"json:\"a\""`,
+			Tags: []types.Tag{{Key: "json", Name: "a", Value: "a", Pos: 1}},
+		},
+		{
+			Lit:    "`json:\"a\" db:a`",
+			Tags:   []types.Tag{{Key: "json", Name: "a", Value: "a", Pos: 2}},
+			Offset: 14,
+			Err:    errTagValueSyntax,
+		},
+		{
+			Lit:    "`json`",
+			Offset: 2,
+			Err:    errTagPairSyntax,
+		},
+		{
+			Lit:    "`:\"a\"`",
+			Offset: 2,
+			Err:    errTagKeySyntax,
+		},
+		{
+			Lit:    "`json:\"a`",
+			Offset: 7,
+			Err:    errTagValueSyntax,
+		},
+	} {
+		tags, pos, err := parseTagList(&ast.BasicLit{ValuePos: 1, Kind: token.STRING, Value: tt.Lit})
+		if err != tt.Err {
+			t.Errorf("%s: expected error %v, found %v", tt.Lit, tt.Err, err)
+		}
+		if err != nil && pos != token.Pos(tt.Offset) {
+			t.Errorf("%s: expected error at %d, found %d", tt.Lit, tt.Offset, pos)
+		}
+		if !reflect.DeepEqual(tags, tt.Tags) {
+			t.Errorf("%s: expected %v, found %v", tt.Lit, tt.Tags, tags)
+		}
+	}
+}
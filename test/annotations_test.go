@@ -0,0 +1,78 @@
+package test
+
+import (
+	"go/scanner"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestAnnotations(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "annotations", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = astra.AnnotationParser{}.Annotate(file)
+	errs, ok := err.(scanner.ErrorList)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected one error, found %v", err)
+	}
+	if pos := errs[0].Pos; pos.Line != 12 || pos.Column != 3 || errs[0].Msg != "malformed annotation @http: parameter method: unterminated quoted string" {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+	iface := file.Interfaces[0]
+	for _, tt := range []struct {
+		Name        string
+		Annotations []types.Annotation
+		Expected    []types.Annotation
+	}{
+		{
+			Name:        "Interface",
+			Annotations: iface.Annotations,
+			Expected:    []types.Annotation{{Name: "microgen", Values: []string{"middleware", "logging"}, Raw: "@microgen middleware, logging"}},
+		},
+		{
+			Name:        "Method",
+			Annotations: iface.Methods[0].Annotations,
+			Expected: []types.Annotation{{
+				Name: "http",
+				Params: []types.AnnotationParam{
+					{Key: "method", Values: []string{"GET"}},
+					{Key: "path", Values: []string{"/users/{id}"}},
+					{Key: "tags", Values: []string{"users", "public api"}},
+				},
+				Raw: `@http method=GET path=/users/{id} tags=[users, "public api"]`,
+			}},
+		},
+		{
+			Name:        "Argument",
+			Annotations: iface.Methods[0].Args[0].Annotations,
+			Expected:    []types.Annotation{{Name: "param", Params: []types.AnnotationParam{{Key: "from", Values: []string{"path"}}}, Raw: "@param from=path"}},
+		},
+		{
+			Name:        "Malformed",
+			Annotations: iface.Methods[1].Annotations,
+		},
+		{
+			Name:        "Field",
+			Annotations: file.Structures[0].Fields[0].Annotations,
+			Expected:    []types.Annotation{{Name: "validate", Values: []string{"required"}, Raw: "@validate required"}},
+		},
+	} {
+		for i := range tt.Annotations {
+			if !tt.Annotations[i].Pos.IsValid() {
+				t.Errorf("%s: position is not valid", tt.Name)
+			}
+			tt.Annotations[i].Pos = 0
+		}
+		if !reflect.DeepEqual(tt.Annotations, tt.Expected) {
+			t.Errorf("%s: expected %+v, found %+v", tt.Name, tt.Expected, tt.Annotations)
+		}
+	}
+	if method, _ := iface.Methods[0].Annotations[0].Param("method"); method != "GET" {
+		t.Errorf("unexpected method %s", method)
+	}
+}
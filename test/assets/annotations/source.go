@@ -0,0 +1,19 @@
+package annotations
+
+// UserService manages users.
+// @microgen middleware, logging
+type UserService interface {
+	// GetUser returns user by id.
+	// @http method=GET path=/users/{id} tags=[users, "public api"]
+	GetUser(
+		id string, // @param from=path
+	) (User, error)
+	/*
+		@http method="POST path=/users
+	*/
+	CreateUser(user User) error
+}
+
+type User struct {
+	ID string // @validate required
+}
@@ -0,0 +1,30 @@
+//go:build !ignore
+// +build !ignore
+
+// Package directives contains directives.
+package directives
+
+import "embed"
+
+//go:generate stringer -type=Kind
+//go:generate go run "./gen tool" -out `kinds.go`
+
+// Kind is a kind.
+//
+//go:generate echo kind
+type Kind int
+
+// Sources of the package.
+//
+//go:embed source.go "source.go"
+var Sources embed.FS
+
+type Struct struct {
+	Field int //nolint:unused,structcheck // unused field
+}
+
+//go:generate echo function
+func Function() {
+	//nolint
+	_ = Sources
+}
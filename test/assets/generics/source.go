@@ -0,0 +1,40 @@
+package generics
+
+import (
+	"context"
+	"io"
+)
+
+type Number interface {
+	~int | ~int64 | float64
+}
+
+type List[T any] struct {
+	Items []T      `json:"items"`
+	Next  *List[T] `json:"next,omitempty"`
+	Pairs map[string]Pair[string, T]
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Service interface {
+	io.Closer
+	Do(ctx context.Context, args ...string) (<-chan error, error)
+}
+
+type Handler func(context.Context, *List[string]) error
+
+func Sum[T Number](values ...T) T {
+	var s T
+	for _, v := range values {
+		s += v
+	}
+	return s
+}
+
+func (l *List[T]) Len() int {
+	return len(l.Items)
+}
@@ -0,0 +1,20 @@
+package imports
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	_ "embed"
+	"strings"
+
+	. "github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"
+	stub "github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"
+)
+
+type Holder struct {
+	Dot     ThisIsStubStructure
+	Aliased *stub.ThisIsStubStructure
+	Builder strings.Builder
+	Local   []Holder
+	Size    C.size_t
+}
@@ -0,0 +1,6 @@
+package other
+
+type Other struct {
+	Remote string `json:"remote"`
+	Deep   int
+}
@@ -0,0 +1,40 @@
+package jsonfields
+
+import "github.com/vetcher/go-astra/test/assets/jsonfields/other"
+
+type Duration int64
+
+type unexportedInt int
+
+type Inner struct {
+	Shared string
+	Deep   int `json:"deep"`
+}
+
+type Tagged struct {
+	Shared string `json:"Shared"`
+}
+
+type conflictA struct {
+	Conflict int
+}
+
+type conflictB struct {
+	Conflict int
+}
+
+type Object struct {
+	Name    string   `json:"name,omitempty"`
+	Skipped string   `json:"-"`
+	Dash    string   `json:"-,"`
+	Count   Duration `json:",string"`
+	Pointer *int     `json:"pointer,string"`
+	Slice   []int    `json:"slice,string"`
+	private int
+	*Inner
+	Tagged
+	conflictA
+	conflictB
+	unexportedInt
+	other.Other
+}
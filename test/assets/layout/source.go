@@ -0,0 +1,39 @@
+package layout
+
+import (
+	"unsafe"
+
+	"github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"
+)
+
+type Point struct {
+	X, Y int16
+}
+
+type ID uint64
+
+type Padded struct {
+	Flag    bool
+	Count   int64
+	Small   int8
+	Name    string
+	Points  [3]Point
+	Hex     [0x10]byte
+	Tags    []string
+	Meta    map[string]interface{}
+	Handler func() error
+	Next    *Padded
+	Raw     unsafe.Pointer
+	ID      ID
+	Stub    thisisstubpackage.ThisIsStubStructure
+	Err     error
+	Ratio   complex64
+	Point
+	Empty struct{}
+}
+
+const N = 8
+
+type Sized struct {
+	B [N]byte
+}
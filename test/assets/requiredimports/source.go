@@ -0,0 +1,37 @@
+package requiredimports
+
+import (
+	"bytes"
+	buf "bytes"
+	ctx "context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Reader interface {
+	io.Reader
+	ReadContext(ctx ctx.Context) (*bytes.Buffer, error)
+}
+
+type Cache[K comparable, V io.Writer] struct {
+	mx    sync.Mutex
+	items map[K]map[string][]chan<- time.Duration
+}
+
+type Handler func(http.ResponseWriter, *http.Request)
+
+func NewCache(ttl time.Duration) *Cache[string, io.Writer] {
+	return nil
+}
+
+func (c *Cache[K, V]) Lock(m *sync.Mutex) {}
+
+var Client *http.Client
+
+type Buffers struct {
+	In  *bytes.Buffer
+	Out *buf.Buffer
+	Err *bytes.Buffer
+}
@@ -0,0 +1,62 @@
+package slice
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type Service interface {
+	Get(ctx context.Context, id ID) (*User, error)
+	List(filter Filter) []User
+}
+
+type ID string
+
+type Role int
+
+const (
+	RoleAdmin Role = iota
+	RoleUser
+)
+
+const Limit = 10
+
+type User struct {
+	ID      ID
+	Role    Role
+	Profile *Profile
+	Tags    map[Tag][]Link[Tag]
+}
+
+func (u *User) Owner() *Owner {
+	return nil
+}
+
+type Owner struct {
+	Name string
+}
+
+type Profile struct {
+	Created time.Time
+}
+
+type Link[T any] struct {
+	Value T
+}
+
+func (l Link[T]) Get() T {
+	return l.Value
+}
+
+type Tag string
+
+type Filter struct {
+	Roles []Role
+}
+
+type Unrelated struct {
+	Reader io.Reader
+}
+
+var DefaultService Service
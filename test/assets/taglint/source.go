@@ -0,0 +1,32 @@
+package taglint
+
+type Base struct {
+	ID      int    `json:"id" db:"id"`
+	Created string `json:"created_at"`
+}
+
+type Audit struct {
+	ID int `json:"id"`
+}
+
+type User struct {
+	Base
+	Audit
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name" json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email" db:"email"`
+	Mail      string `json:"mail" db:"email"`
+	password  string `json:"password"`
+	Broken    string `json:"broken" db:broken`
+}
+
+type Settings struct {
+	Embedded `yaml:",inline"`
+	Theme    string `yaml:"theme"`
+}
+
+type Embedded struct {
+	Theme string `yaml:"theme"`
+	Lang  string `yaml:"lang"`
+}
@@ -0,0 +1,11 @@
+package tags
+
+type Tagged struct {
+	A, B   int    `json:"a,omitempty" db:"a"`
+	C      string `yaml:"c" json:"c,string" json:"duplicate"`
+	D      string
+	Broken string `json:"broken" db:broken`
+	Inner  `json:"-"`
+}
+
+type Inner struct{}
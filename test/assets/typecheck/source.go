@@ -0,0 +1,22 @@
+package typecheck
+
+import (
+	. "io"
+
+	"github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"
+)
+
+var VarA = len("abc")
+
+var VarB = thisisstubpackage.ThisIsStubStructure{}
+
+type StructOne struct {
+	FieldOne Reader
+	FieldTwo []*thisisstubpackage.ThisIsStubStructure
+}
+
+type Generic[T any] struct {
+	Value T
+}
+
+type MyInt int
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func setCacheDir(t *testing.T) string {
+	dir := t.TempDir()
+	if err := astra.SetCacheDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { astra.SetCacheDir("") })
+	return dir
+}
+
+func cacheEntries(t *testing.T, dir string) int {
+	infos, err := ioutil.ReadDir(filepath.Join(dir, "files"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(infos)
+}
+
+func TestCache(t *testing.T) {
+	dir := setCacheDir(t)
+	for _, path := range []string{"full", "interfaces", "structures", "tags", "generics", "directives", "floating"} {
+		filename := filepath.Join(assetsDir, path, source)
+		parsed, err := astra.ParseFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cached, err := astra.ParseFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := json.Marshal(parsed)
+		actual, _ := json.Marshal(cached)
+		if string(expected) != string(actual) {
+			t.Fatalf("%s: cached file differs:\n%s\n\n%s", path, expected, actual)
+		}
+		if parsed.Fingerprint() != cached.Fingerprint() {
+			t.Errorf("%s: fingerprints differ", path)
+		}
+	}
+	if n := cacheEntries(t, dir); n != 7 {
+		t.Errorf("expect 7 cached files, found %d", n)
+	}
+
+	src := []byte("package cached\n\nimport \"fmt\"\n\ntype A struct {\n\tS fmt.Stringer `json:\"s\"`\n}\n\nfunc (a A) String() string { return \"\" }\n")
+	parsed, err := astra.ParseSource("a.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := astra.ParseSource("a.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Structures[0].Fields[0].Type.(types.TImport).Import != cached.Imports[0] {
+		t.Error("imports of types should be shared with imports of file")
+	}
+	if len(cached.Structures[0].Methods) != 1 || cached.Structures[0].Methods[0] != &cached.Methods[0] {
+		t.Error("methods should be linked to structures")
+	}
+	expected := parsed.Position(parsed.Structures[0].Fields[0].TagPos)
+	if pos := cached.Position(cached.Structures[0].Fields[0].TagPos); pos != expected || pos.String() != "a.go:6:17" {
+		t.Errorf("expect position %s, found %s", expected, pos)
+	}
+	if _, err := astra.ParseSource("a.go", append(src, "\ntype B int\n"...)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := astra.ParseSource("a.go", src, astra.IgnoreMethods); err != nil {
+		t.Fatal(err)
+	}
+	if n := cacheEntries(t, dir); n != 10 {
+		t.Errorf("changed sources and options should be cached separately, found %d entries", n)
+	}
+}
+
+func TestCacheGetPackage(t *testing.T) {
+	setCacheDir(t)
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package pkg\n\ntype A struct{ X int }\n")
+	write("b.go", "package pkg\n\nfunc (a *A) Get() int { return a.X }\n")
+	for i := 0; i < 2; i++ {
+		file, err := astra.GetPackage(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(file.Structures) != 1 || len(file.Structures[0].Methods) != 1 {
+			t.Fatalf("unexpected file: %v", file)
+		}
+	}
+	write("b.go", "package pkg\n\nfunc (a *A) Get() int { return a.X }\n\nfunc (a *A) Set(x int) { a.X = x }\n")
+	file, err := astra.GetPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Structures[0].Methods) != 2 {
+		t.Errorf("changed file should invalidate cache, found %d methods", len(file.Structures[0].Methods))
+	}
+	if err := os.Remove(filepath.Join(dir, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	file, err = astra.GetPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Methods) != 0 {
+		t.Errorf("removed file should invalidate cache, found %d methods", len(file.Methods))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(src string) string {
+		file, err := astra.ParseSource("api.go", []byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return file.Fingerprint()
+	}
+	base := fingerprint(`package api
+
+import "context"
+
+// Service does things.
+type Service interface {
+	Do(ctx context.Context, id int) (string, error)
+}
+
+type Request struct {
+	ID    int ` + "`json:\"id\"`" + `
+	cache map[int]string
+}
+
+func New() Service { return nil }
+
+func helper() {}
+`)
+	same := fingerprint(`package api
+
+import stdctx "context"
+
+func New() Service {
+	return nil
+}
+
+type Request struct {
+	// ID of request.
+	ID    int ` + "`json:\"id\"`" + `
+	cache []string
+	extra bool
+}
+
+type Service interface {
+	Do(c stdctx.Context, requestID int) (string, error)
+}
+
+func helper(x int) {}
+
+type unexported struct{}
+`)
+	if base != same {
+		t.Error("docs, unexported declarations, names of arguments, order and aliases should not change fingerprint")
+	}
+	for _, changed := range []string{
+		"package api\n\ntype Service interface{}\n\ntype Request struct {\n\tID int `json:\"id\"`\n}\n\nfunc New() Service { return nil }\n",
+		"package api\n\nimport \"context\"\n\ntype Service interface {\n\tDo(ctx context.Context, id int) (string, error)\n}\n\ntype Request struct {\n\tID int `json:\"request_id\"`\n}\n\nfunc New() Service { return nil }\n",
+		"package api\n\nimport \"context\"\n\ntype Service interface {\n\tDo(ctx context.Context, id int) (string, error)\n}\n\ntype Request struct {\n\tID int `json:\"id\"`\n}\n\nfunc New() *Service { return nil }\n",
+	} {
+		if fingerprint(changed) == base {
+			t.Errorf("fingerprint should change for:\n%s", changed)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/vetcher/go-astra"
+)
+
+func TestParseTypedIota(t *testing.T) {
+	src := []byte(`package roles
+
+type Role int
+
+type Size uint64
+
+const (
+	Admin Role = iota
+	User
+	Guest
+)
+
+const (
+	KB Size = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+`)
+	file, err := astra.ParseSource("roles.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Admin Role", "User Role", "Guest Role", "KB Size", "MB Size", "GB Size"}
+	if len(file.Constants) != len(expected) {
+		t.Fatalf("expected %d constants, found %d", len(expected), len(file.Constants))
+	}
+	for i := range expected {
+		if actual := file.Constants[i].String(); actual != expected[i] {
+			t.Errorf("expected %s, found %s", expected[i], actual)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestDirectives(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "directives", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type directive struct {
+		Name string
+		Args []string
+	}
+	convert := func(ds []types.Directive) (result []directive) {
+		for _, d := range ds {
+			if !d.Pos.IsValid() {
+				t.Errorf("%s: position is not valid", d.Raw)
+			}
+			result = append(result, directive{Name: d.Name, Args: d.Args})
+		}
+		return
+	}
+	for _, tt := range []struct {
+		Name       string
+		Directives []types.Directive
+		Expected   []directive
+	}{
+		{
+			Name:       "File",
+			Directives: file.Directives,
+			Expected: []directive{
+				{Name: "go:build", Args: []string{"!ignore"}},
+				{Name: "+build", Args: []string{"!ignore"}},
+				{Name: "go:generate", Args: []string{"stringer", "-type=Kind"}},
+				{Name: "go:generate", Args: []string{"go", "run", "./gen tool", "-out", "kinds.go"}},
+				{Name: "nolint"},
+			},
+		},
+		{
+			Name:       "Type",
+			Directives: file.Types[0].Directives,
+			Expected:   []directive{{Name: "go:generate", Args: []string{"echo", "kind"}}},
+		},
+		{
+			Name:       "Field",
+			Directives: file.Structures[0].Fields[0].Directives,
+			Expected:   []directive{{Name: "nolint", Args: []string{"unused", "structcheck"}}},
+		},
+		{
+			Name:       "Generate",
+			Directives: file.GoGenerate(),
+			Expected: []directive{
+				{Name: "go:generate", Args: []string{"stringer", "-type=Kind"}},
+				{Name: "go:generate", Args: []string{"go", "run", "./gen tool", "-out", "kinds.go"}},
+				{Name: "go:generate", Args: []string{"echo", "kind"}},
+				{Name: "go:generate", Args: []string{"echo", "function"}},
+			},
+		},
+	} {
+		actual := convert(tt.Directives)
+		if !reflect.DeepEqual(actual, tt.Expected) {
+			t.Errorf("%s: expected %v, found %v", tt.Name, tt.Expected, actual)
+		}
+	}
+	if patterns := file.Vars[0].EmbedPatterns(); !reflect.DeepEqual(patterns, []string{"source.go", "source.go"}) {
+		t.Errorf("unexpected embed patterns %v", patterns)
+	}
+	if text := file.Types[0].Comments.Text(); text != "Kind is a kind." {
+		t.Errorf("directives in comment text: %q", text)
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+)
+
+func TestFloatingComments(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "floating", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var floating []string
+	for _, c := range file.FloatingComments {
+		if len(c.Positions) != len(c.Raw) || !c.Positions[0].IsValid() {
+			t.Errorf("%v: positions are not valid", c.Raw)
+		}
+		floating = append(floating, c.Text())
+	}
+	expected := []string{
+		"Copyright 2018 The go-astra Authors. All rights reserved.\nUse of this source code is governed by MIT license.",
+		"Code generated by floatgen. DO NOT EDIT.",
+		"---- Types ----",
+		"---- Functions ----",
+		"Trailing comment of the file.",
+	}
+	if !reflect.DeepEqual(floating, expected) {
+		t.Errorf("expected floating comments %q, found %q", expected, floating)
+	}
+	if file.License == nil || file.License.Text() != expected[0] {
+		t.Errorf("unexpected license %v", file.License)
+	}
+	if !file.IsGenerated {
+		t.Error("file should be generated")
+	}
+	if text := file.Comments.Text(); text != "Package floating is a package with comments outside of declarations." {
+		t.Errorf("unexpected package documentation %q", text)
+	}
+}
+
+func TestFloatingCommentsOfPackage(t *testing.T) {
+	file, err := astra.GetPackage(filepath.Join(assetsDir, "floating"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.FloatingComments) != 5 || file.License == nil || !file.IsGenerated {
+		t.Errorf("comments of package are lost: %d floating comments, license %v, generated %v",
+			len(file.FloatingComments), file.License, file.IsGenerated)
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestConvertPackage(t *testing.T) {
+	dir := filepath.Join(assetsDir, "generics")
+	fset := token.NewFileSet()
+	tree, err := parser.ParseFile(fset, filepath.Join(dir, source), nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := astra.ParseAstFile(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := resolver.Check(fset, []*ast.File{tree})
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted := astra.ConvertPackage(pkg)
+
+	expected := map[string]string{}
+	for _, s := range parsed.Structures {
+		expected[s.Name] = s.String()
+	}
+	for _, i := range parsed.Interfaces {
+		expected[i.Name] = i.String()
+	}
+	for _, f := range parsed.Functions {
+		expected[f.Name] = f.String()
+	}
+	for _, tp := range parsed.Types {
+		expected[tp.Name] = tp.Type.String()
+	}
+	for _, m := range parsed.Methods {
+		expected[m.Name] = m.String()
+	}
+	actual := map[string]string{}
+	for _, s := range converted.Structures {
+		actual[s.Name] = s.String()
+		if len(s.Methods) != len(parsed.FindDecl(s.Name).(*types.Struct).Methods) {
+			t.Errorf("%s: methods are not linked", s.Name)
+		}
+	}
+	for _, i := range converted.Interfaces {
+		actual[i.Name] = i.String()
+	}
+	for _, f := range converted.Functions {
+		actual[f.Name] = f.String()
+	}
+	for _, tp := range converted.Types {
+		actual[tp.Name] = tp.Type.String()
+	}
+	for _, m := range converted.Methods {
+		actual[m.Name] = m.String()
+	}
+	for name, exp := range expected {
+		if actual[name] != exp {
+			t.Errorf("%s:\nexpected %s\nfound    %s", name, exp, actual[name])
+		}
+	}
+	if len(converted.Imports) != 2 || converted.Imports[0].Package != "context" || converted.Imports[1].Package != "io" {
+		t.Errorf("unexpected imports %v", converted.Imports)
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestParseGenerics(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "generics", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeParams := func(params []types.Variable) (result []string) {
+		for _, p := range params {
+			result = append(result, p.String())
+		}
+		return
+	}
+	list := file.FindDecl("List").(*types.Struct)
+	if params := typeParams(list.TypeParams); len(params) != 1 || params[0] != "T any" {
+		t.Errorf("unexpected type params of List: %q", params)
+	}
+	for i, expected := range []string{"[]T", "*List[T]", "map[string]Pair[string, T]"} {
+		if actual := list.Fields[i].Type.String(); actual != expected {
+			t.Errorf("field %s: expected %s, found %s", list.Fields[i].Name, expected, actual)
+		}
+	}
+	next := list.Fields[1].Type.(types.TPointer).Next.(types.TName)
+	if next.TypeName != "List" || len(next.TypeArgs) != 1 || next.TypeArgs[0].String() != "T" {
+		t.Errorf("unexpected instantiated type: %#v", next)
+	}
+	if params := typeParams(file.FindDecl("Pair").(*types.Struct).TypeParams); len(params) != 2 || params[0] != "K comparable" || params[1] != "V any" {
+		t.Errorf("unexpected type params of Pair: %q", params)
+	}
+
+	number := file.FindDecl("Number").(*types.Interface)
+	if len(number.Methods) != 0 || len(number.Interfaces) != 1 {
+		t.Fatalf("unexpected constraint: %v", number)
+	}
+	union, ok := number.Interfaces[0].Type.(types.TUnion)
+	if !ok || len(union.Terms) != 3 || !union.Terms[0].Tilde || !union.Terms[1].Tilde || union.Terms[2].Tilde {
+		t.Errorf("unexpected union: %#v", number.Interfaces[0].Type)
+	}
+	if s := union.String(); s != "~int | ~int64 | float64" {
+		t.Errorf("unexpected union string: %s", s)
+	}
+	service := file.FindDecl("Service").(*types.Interface)
+	if len(service.Methods) != 1 || len(service.Interfaces) != 1 || service.Interfaces[0].Type.String() != "io.Closer" {
+		t.Errorf("embedded interfaces and methods should be separated: %v", service)
+	}
+
+	sum := file.FindDecl("Sum").(*types.Function)
+	if params := typeParams(sum.TypeParams); len(params) != 1 || params[0] != "T Number" {
+		t.Errorf("unexpected type params of Sum: %q", params)
+	}
+	if len(file.Methods) == 0 || file.Methods[0].Receiver.Type.String() != "*List[T]" {
+		t.Fatalf("unexpected methods: %v", file.Methods)
+	}
+	if len(list.Methods) == 0 || list.Methods[0] != &file.Methods[0] {
+		t.Error("methods of generic types should be linked to them")
+	}
+}
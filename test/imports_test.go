@@ -0,0 +1,126 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestImportKinds(t *testing.T) {
+	dir := filepath.Join(assetsDir, "imports")
+	file, err := astra.ParseFile(filepath.Join(dir, source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, imp := range file.Imports {
+		actual = append(actual, string(imp.Kind)+" "+imp.Name+" "+imp.RealName+" "+imp.LocalName())
+	}
+	expected := []string{
+		"cgo C C C",
+		"blank _ embed ",
+		"normal strings strings strings",
+		"dot . thisisstubpackage ",
+		"aliased stub thisisstubpackage stub",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected imports %q, found %q", expected, actual)
+	}
+
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resolver.ResolveDotImports(file); err != nil {
+		t.Fatal(err)
+	}
+	var fields []string
+	for _, field := range file.Structures[0].Fields {
+		fields = append(fields, field.Type.String())
+	}
+	expected = []string{"ThisIsStubStructure", "*stub.ThisIsStubStructure", "strings.Builder", "[]Holder", "C.size_t"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected fields %q, found %q", expected, fields)
+	}
+	if imp := types.TypeImport(file.Structures[0].Fields[0].Type); imp == nil || imp.Kind != types.ImportDot {
+		t.Errorf("type from dot import is not resolved: %#v", file.Structures[0].Fields[0].Type)
+	}
+}
+
+func TestResolveDotImportsOption(t *testing.T) {
+	dir := filepath.Join(assetsDir, "imports")
+	file, err := astra.ParseFile(filepath.Join(dir, source), astra.ResolveDotImports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := astra.GetPackage(dir, astra.ResolveDotImports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages, err := astra.LoadPackages([]string{"./" + filepath.ToSlash(dir)}, astra.ResolveDotImports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*types.File{file, pkg, packages[0].File} {
+		if imp := types.TypeImport(f.Structures[0].Fields[0].Type); imp == nil || imp.Kind != types.ImportDot {
+			t.Errorf("type from dot import is not resolved: %#v", f.Structures[0].Fields[0].Type)
+		}
+	}
+
+	resolver, err := astra.NewResolver(dir, astra.ResolveDotImports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := resolver.Package("github.com/vetcher/go-astra/test/assets/imports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := types.TypeImport(imported.Structures[0].Fields[0].Type); imp == nil || imp.Kind != types.ImportDot {
+		t.Errorf("type from dot import of imported package is not resolved: %#v", imported.Structures[0].Fields[0].Type)
+	}
+}
+
+func TestRequiredImports(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "requiredimports", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := func(imports []*types.Import) []string {
+		var paths []string
+		for _, imp := range imports {
+			if !containsImport(file.Imports, imp) {
+				t.Errorf("import of %s is not shared with the file", imp.Package)
+			}
+			paths = append(paths, imp.Name+" "+imp.Package)
+		}
+		return paths
+	}
+	for _, c := range []struct {
+		decls    []types.Decl
+		expected []string
+	}{
+		{nil, nil},
+		{[]types.Decl{file.FindDecl("Reader")}, []string{"ctx context", "bytes bytes", "io io"}},
+		{[]types.Decl{file.FindDecl("Cache")}, []string{"io io", "sync sync", "time time"}},
+		{[]types.Decl{file.FindDecl("Handler"), file.FindDecl("Client")}, []string{"http net/http"}},
+		{[]types.Decl{file.FindDecl("NewCache"), file.FindDecl("Cache")}, []string{"time time", "io io", "sync sync"}},
+		{[]types.Decl{file.Methods[0]}, []string{"sync sync"}},
+		{[]types.Decl{file.FindDecl("Buffers")}, []string{"bytes bytes", "buf bytes"}},
+	} {
+		if actual := paths(file.RequiredImports(c.decls...)); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected %q, found %q", c.expected, actual)
+		}
+	}
+}
+
+func containsImport(imports []*types.Import, imp *types.Import) bool {
+	for _, i := range imports {
+		if i == imp {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/test/assets/jsonfields"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestJSONFields(t *testing.T) {
+	dir := filepath.Join(assetsDir, "jsonfields")
+	file, err := astra.ParseFile(filepath.Join(dir, source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := file.FindDecl("Object").(*types.Struct)
+	fields, err := astra.JSONFields(file, *object, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual, quoted []string
+	for _, f := range fields {
+		actual = append(actual, f.Name)
+		if f.String {
+			quoted = append(quoted, f.Name)
+		}
+	}
+	if expected := encodedKeys(t, jsonfields.Object{Name: "name", Inner: &jsonfields.Inner{}}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected fields %v, found %v", expected, actual)
+	}
+	if expected := []string{"Count", "pointer"}; !reflect.DeepEqual(quoted, expected) {
+		t.Errorf("expected quoted fields %v, found %v", expected, quoted)
+	}
+	if !fields[0].OmitEmpty || !reflect.DeepEqual(fields[len(fields)-1].Index, []int{12, 1}) {
+		t.Errorf("unexpected options or index: %v", fields)
+	}
+	if _, err := astra.JSONFields(file, *object, nil); err == nil {
+		t.Error("expect error for embedded structure from other package without resolver")
+	}
+}
+
+// Returns keys of JSON object in order of encoding.
+func encodedKeys(t *testing.T, v interface{}) []string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/test/assets/layout"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestStructLayout(t *testing.T) {
+	dir := filepath.Join(assetsDir, "layout")
+	file, err := astra.ParseFile(filepath.Join(dir, source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := file.FindDecl("Padded").(*types.Struct)
+	layouter, err := astra.NewLayouter(runtime.GOARCH, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := layouter.StructLayout(file, *padded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v layout.Padded
+	if actual.Size != int64(unsafe.Sizeof(v)) || actual.Align != int64(unsafe.Alignof(v)) {
+		t.Errorf("expected size %d and align %d, found %d and %d", unsafe.Sizeof(v), unsafe.Alignof(v), actual.Size, actual.Align)
+	}
+	rt := reflect.TypeOf(v)
+	for i, field := range actual.Fields {
+		expected := rt.Field(i)
+		if field.Offset != int64(expected.Offset) || field.Size != int64(expected.Type.Size()) || field.Align != int64(expected.Type.Align()) {
+			t.Errorf("%s: expected offset %d, size %d, align %d, found %d, %d, %d", field.Name,
+				expected.Offset, expected.Type.Size(), expected.Type.Align(), field.Offset, field.Size, field.Align)
+		}
+	}
+	if actual.Padding == 0 || actual.OptimalSize >= actual.Size || len(actual.OptimalOrder) != len(actual.Fields) {
+		t.Errorf("unexpected optimization: %+v", actual)
+	}
+	if actual.OptimalOrder[0] != "Empty" {
+		t.Errorf("zero sized field should be the first, found order %v", actual.OptimalOrder)
+	}
+
+	layouter386, err := astra.NewLayouter("386", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, align, err := layouter386.SizeOf(file, types.TName{TypeName: "Padded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 120 || align != 4 {
+		t.Errorf("386: expected size 120 and align 4, found %d and %d", size, align)
+	}
+	sized := file.FindDecl("Sized").(*types.Struct)
+	if l := sized.Fields[0].Type.(types.TArray); l.LenExpr != "N" || l.IsSlice || l.String() != "[N]byte" {
+		t.Errorf("unexpected array with constant length: %#v", l)
+	}
+	if _, err := layouter.StructLayout(file, *sized); err == nil || !strings.Contains(err.Error(), astra.ErrUnknownSize.Error()) {
+		t.Errorf("expect ErrUnknownSize for array with constant length, found %v", err)
+	}
+	if _, err := astra.NewLayouter("unknown", nil); err == nil {
+		t.Error("expect error for unknown GOARCH")
+	}
+}
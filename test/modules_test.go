@@ -0,0 +1,217 @@
+package test
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+var modulesDir = filepath.Join("testdata", "modules")
+
+func TestImportPath(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	for dir, expected := range map[string]string{
+		filepath.Join(modulesDir, "app"):                                   "example.com/app",
+		filepath.Join(modulesDir, "app", "server"):                         "example.com/app/server",
+		filepath.Join(modulesDir, "app", "nested"):                         "example.com/app/nested",
+		filepath.Join(modulesDir, "app", "nested", "inner"):                "example.com/app/nested/inner",
+		filepath.Join(modulesDir, "app", "vendor", "github.com", "x", "y"): "github.com/x/y",
+		filepath.Join(modulesDir, "lib", "util"):                           "example.com/lib/util",
+		filepath.Join(build.Default.GOROOT, "src", "net", "http"):          "net/http",
+	} {
+		actual, err := astra.ImportPath(dir)
+		if err != nil {
+			t.Errorf("%s: %v", dir, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %s, found %s", dir, expected, actual)
+		}
+	}
+	actual, err := astra.ResolvePackagePath(filepath.Join(modulesDir, "app", "server", "server.go"))
+	if err != nil || actual != "example.com/app/server" {
+		t.Errorf("unexpected path of server.go: %s, %v", actual, err)
+	}
+}
+
+func TestResolverWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	modules, err := filepath.Abs(modulesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(modules, "app", "server")
+	// names of imported packages are found from the directory of the file, not from the working directory
+	file, err := astra.ParseFile(filepath.Join(dir, "server.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := file.Imports[1]; imp.Name != "extension" || imp.Guessed {
+		t.Errorf("name of replaced package is not found: %#v", imp)
+	}
+	// the same import path outside of workspace is not replaced, so name is not taken from cache
+	outside, err := astra.ParseSource(filepath.Join(t.TempDir(), "a.go"), []byte("package a\n\nimport \"example.com/ext\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := outside.Imports[0]; imp.Name != "ext" || !imp.Guessed {
+		t.Errorf("name of package from another module is used: %#v", imp)
+	}
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for importPath, expected := range map[string]string{
+		"example.com/lib/util":         filepath.Join(modules, "lib", "util"),
+		"example.com/app/nested/inner": filepath.Join(modules, "app", "nested", "inner"),
+		"example.com/app":              filepath.Join(modules, "app"),
+		"example.com/ext":              filepath.Join(modules, "ext"),
+		"github.com/x/y":               filepath.Join(modules, "app", "vendor", "github.com", "x", "y"),
+	} {
+		actual, err := resolver.PackageDir(importPath)
+		if err != nil {
+			t.Errorf("%s: %v", importPath, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %s, found %s", importPath, expected, actual)
+		}
+	}
+	for _, field := range file.Structures[0].Fields {
+		decl, err := resolver.ResolveType(field.Type)
+		if err != nil {
+			t.Errorf("%s: %v", field.Name, err)
+			continue
+		}
+		if _, ok := decl.(*types.Struct); !ok {
+			t.Errorf("%s: expect *types.Struct, found %T", field.Name, decl)
+		}
+	}
+
+	t.Setenv("GOWORK", "off")
+	resolver, err = astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir, err := resolver.PackageDir("example.com/lib/util"); err == nil {
+		t.Errorf("workspace is not disabled: %s", dir)
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	modules, err := filepath.Abs(modulesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		dir      string
+		patterns []string
+		expected []string
+	}{
+		{
+			dir:      modules,
+			patterns: []string{"./..."},
+			expected: []string{"example.com/app", "example.com/app/server", "example.com/lib", "example.com/lib/internal/conv", "example.com/lib/util"},
+		},
+		{
+			dir:      filepath.Join(modules, "app"),
+			patterns: []string{"example.com/lib/...", "./server", "example.com/lib/util", "example.com/ext"},
+			expected: []string{"example.com/lib", "example.com/lib/internal/conv", "example.com/lib/util", "example.com/app/server", "example.com/ext"},
+		},
+		{
+			dir:      filepath.Join(modules, "app"),
+			patterns: []string{"./nested/..."},
+			expected: []string{"example.com/app/nested", "example.com/app/nested/inner"},
+		},
+	} {
+		chdir(t, c.dir)
+		packages, err := astra.LoadPackages(c.patterns)
+		if err != nil {
+			t.Errorf("%v: %v", c.patterns, err)
+			continue
+		}
+		var actual []string
+		for _, pkg := range packages {
+			actual = append(actual, pkg.ImportPath)
+			if pkg.File == nil || pkg.File.Name != path.Base(pkg.Dir) && pkg.File.Name != "extension" {
+				t.Errorf("%s: unexpected file of package %v", pkg.ImportPath, pkg.File)
+			}
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%v: expected %q, found %q", c.patterns, c.expected, actual)
+		}
+	}
+	chdir(t, modules)
+	packages, err := astra.LoadPackages([]string{"./app/server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := packages[0].File.Structures[0].Fields; fields[2].Type.String() != "extension.Option" {
+		t.Errorf("unexpected type of field: %s", fields[2].Type)
+	}
+	if _, err := astra.LoadPackages([]string{"./tools/..."}); err == nil {
+		t.Error("packages outside of workspace modules are loaded")
+	}
+}
+
+// Changes working directory until the end of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestResolverModuleCache(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	dir := t.TempDir()
+	write := func(name, src string) {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main/go.mod", "module example.com/main\n\nrequire example.com/dep v1.0.0\n")
+	write("main/main.go", "package main\n")
+	write("cache/example.com/dep@v1.0.0/dep.go", "package dep\n")
+	write("gopath/pkg/mod/example.com/dep@v1.0.0/dep.go", "package dep\n")
+
+	defer func(gopath string) { build.Default.GOPATH = gopath }(build.Default.GOPATH)
+	build.Default.GOPATH = filepath.Join(dir, "gopath")
+	for _, c := range []struct{ modCache, expected string }{
+		{filepath.Join(dir, "cache"), filepath.Join(dir, "cache", "example.com", "dep@v1.0.0")},
+		{"", filepath.Join(dir, "gopath", "pkg", "mod", "example.com", "dep@v1.0.0")},
+	} {
+		t.Setenv("GOMODCACHE", c.modCache)
+		resolver, err := astra.NewResolver(filepath.Join(dir, "main"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := resolver.PackageDir("example.com/dep")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != c.expected {
+			t.Errorf("GOMODCACHE=%q: expected %s, found %s", c.modCache, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vetcher/go-astra"
+)
+
+func TestParseSource(t *testing.T) {
+	src := []byte("package memory\n\ntype Buffer struct {\n\tData []byte\n}\n")
+	file, err := astra.ParseSource("buffer.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "memory" || len(file.Structures) != 1 || file.Structures[0].Name != "Buffer" {
+		t.Errorf("unexpected file: %v", file)
+	}
+	if pos := file.Position(file.Structures[0].Fields[0].TypeEnd); pos.String() != "buffer.go:4:13" {
+		t.Errorf("unexpected position: %s", pos)
+	}
+	file, err = astra.ParseReader("buffer.go", strings.NewReader(string(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Structures) != 1 {
+		t.Errorf("unexpected file: %v", file)
+	}
+	if _, err := astra.ParseSource("broken.go", []byte("package")); err == nil {
+		t.Error("expect error for malformed source")
+	}
+}
+
+func TestGetPackageFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/pkg/a.go":       {Data: []byte("package pkg\n\ntype A struct{ X int }\n")},
+		"fixtures/pkg/b.go":       {Data: []byte("package pkg\n\nfunc (A) B() {}\n")},
+		"fixtures/pkg/a_test.go":  {Data: []byte("package pkg_test\n\ntype Test struct{}\n")},
+		"fixtures/pkg/ignored.go": {Data: []byte("//go:build ignore\n\npackage main\n")},
+		"fixtures/pkg/sub/sub.go": {Data: []byte("package sub\n")},
+	}
+	file, err := astra.GetPackageFS(fsys, "fixtures/pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "pkg" || len(file.Structures) != 1 || len(file.Structures[0].Methods) != 1 {
+		t.Errorf("unexpected package: %v", file)
+	}
+	if pos := file.Position(file.Structures[0].Fields[0].TypeEnd); pos.Filename != "fixtures/pkg/a.go" {
+		t.Errorf("unexpected position: %s", pos)
+	}
+	if _, err := astra.GetPackageFS(fsys, "fixtures/missing"); err == nil {
+		t.Error("expect error for missing directory")
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	disk := filepath.Join(root, "disk.go")
+	if err := ioutil.WriteFile(disk, []byte("package disk\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf := astra.Config{Overlay: map[string][]byte{
+		disk:                                 []byte("package overlaid\n"),
+		filepath.Join(root, "go.mod"):        []byte("module example.com/overlay\n"),
+		filepath.Join(root, "dep", "dep.go"): []byte("package dependency\n\ntype Thing struct{}\n"),
+		filepath.Join(root, "pkg", "pkg.go"): []byte("package pkg\n\nimport \"example.com/overlay/dep\"\n\ntype Holder struct {\n\tThing dependency.Thing\n}\n"),
+	}}
+	chdir(t, root)
+
+	file, err := conf.ParseFile(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "overlaid" {
+		t.Errorf("file is not replaced by overlay: %s", file.Name)
+	}
+	checked := conf
+	checked.Options = []astra.Option{astra.CheckTypes}
+	file, err = checked.ParseFile(filepath.Join("pkg", "pkg.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := file.Imports[0]; imp.Name != "dependency" || imp.Guessed {
+		t.Errorf("name of package from overlay is not found: %#v", imp)
+	}
+	if info := file.Structures[0].Fields[0].TypeInfo; info == nil || info.Package != "example.com/overlay/dep" {
+		t.Errorf("types are not checked with overlay: %#v", info)
+	}
+	pkg, err := conf.GetPackage("dep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name != "dependency" || len(pkg.Structures) != 1 {
+		t.Errorf("unexpected package: %v", pkg)
+	}
+	if path, err := conf.ImportPath("pkg"); err != nil || path != "example.com/overlay/pkg" {
+		t.Errorf("unexpected import path: %s, %v", path, err)
+	}
+	packages, err := conf.LoadPackages([]string{"./..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, p := range packages {
+		paths = append(paths, p.ImportPath)
+	}
+	if expected := []string{"example.com/overlay", "example.com/overlay/dep", "example.com/overlay/pkg"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected packages %q, found %q", expected, paths)
+	}
+
+	// overlay is not visible for other loads, names of packages, which are found with it, are not shared
+	file, err = astra.ParseFile(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "disk" {
+		t.Errorf("overlay is used by other loads: %s", file.Name)
+	}
+	file, err = astra.ParseSource(filepath.Join(root, "pkg", "pkg.go"), []byte("package pkg\n\nimport \"example.com/overlay/dep\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp := file.Imports[0]; imp.Name != "dep" || !imp.Guessed {
+		t.Errorf("name of package from overlay is used by other loads: %#v", imp)
+	}
+	if _, err := astra.GetPackage("dep"); err == nil {
+		t.Error("package from overlay is loaded without it")
+	}
+}
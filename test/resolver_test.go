@@ -0,0 +1,41 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestResolver(t *testing.T) {
+	dir := filepath.Join(assetsDir, "full")
+	file, err := astra.ParseFile(filepath.Join(dir, source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := astra.NewResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	structTwo, ok := file.FindDecl("StructTwo").(*types.Struct)
+	if !ok {
+		t.Fatal("StructTwo not found")
+	}
+	for _, field := range structTwo.Fields[:2] {
+		decl, err := resolver.ResolveType(field.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, ok := decl.(*types.Struct)
+		if !ok {
+			t.Fatalf("%s: expect *types.Struct, found %T", field.Name, decl)
+		}
+		if s.Name != "ThisIsStubStructure" || len(s.Fields) != 1 || s.Fields[0].Name != "ThisIsStubField" {
+			t.Errorf("%s: unexpected declaration %s", field.Name, s.String())
+		}
+	}
+	if _, err := resolver.Resolve(types.TImport{Import: file.Imports[2], Next: types.TName{TypeName: "NotExist"}}); err == nil {
+		t.Error("expect error for not existing declaration")
+	}
+}
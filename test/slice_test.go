@@ -0,0 +1,98 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestSlice(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "slice", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		names    []string
+		options  []astra.SliceOption
+		expected []string
+	}{
+		{
+			names: []string{"Service"},
+			expected: []string{
+				"import context", "import time", "interface Service",
+				"struct User", "struct Profile", "struct Link", "struct Filter", "type ID", "type Role", "type Tag",
+			},
+		},
+		{
+			names:   []string{"Service"},
+			options: []astra.SliceOption{astra.SliceMethods, astra.SliceEnums},
+			expected: []string{
+				"import context", "import time", "const RoleAdmin", "const RoleUser", "interface Service",
+				"struct User", "struct Owner", "struct Profile", "struct Link", "struct Filter",
+				"method Owner", "method Get", "type ID", "type Role", "type Tag",
+			},
+		},
+		{
+			names:   []string{"DefaultService", "Unrelated", "Limit"},
+			options: []astra.SliceOption{astra.SliceEnums},
+			expected: []string{
+				"import context", "import time", "import io", "const RoleAdmin", "const RoleUser", "const Limit",
+				"var DefaultService", "interface Service", "struct User", "struct Profile", "struct Link", "struct Filter",
+				"struct Unrelated", "type ID", "type Role", "type Tag",
+			},
+		},
+	} {
+		slice, err := astra.Slice(file, c.names, c.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := sliceDecls(slice); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%v: expected %q, found %q", c.names, c.expected, actual)
+		}
+	}
+
+	slice, err := astra.Slice(file, []string{"User"}, astra.SliceMethods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slice.Structures[0].Methods) != 1 || slice.Structures[0].Methods[0].Name != "Owner" {
+		t.Errorf("methods are not linked: %v", slice.Structures[0].Methods)
+	}
+	if len(file.Structures[0].Methods) != 1 {
+		t.Errorf("methods of the file are changed: %v", file.Structures[0].Methods)
+	}
+
+	if _, err := astra.Slice(file, []string{"Missing"}); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func sliceDecls(f *types.File) []string {
+	var decls []string
+	for _, imp := range f.Imports {
+		decls = append(decls, "import "+imp.Package)
+	}
+	for _, c := range f.Constants {
+		decls = append(decls, "const "+c.Name)
+	}
+	for _, v := range f.Vars {
+		decls = append(decls, "var "+v.Name)
+	}
+	for _, i := range f.Interfaces {
+		decls = append(decls, "interface "+i.Name)
+	}
+	for _, s := range f.Structures {
+		decls = append(decls, "struct "+s.Name)
+	}
+	for _, m := range f.Methods {
+		decls = append(decls, "method "+m.Name)
+	}
+	for _, typ := range f.Types {
+		decls = append(decls, "type "+typ.Name)
+	}
+	return decls
+}
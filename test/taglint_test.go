@@ -0,0 +1,34 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+)
+
+func TestTagLinter(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "taglint", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, issue := range (astra.TagLinter{Keys: []string{"json", "yaml"}}).Lint(file) {
+		actual = append(actual, fmt.Sprintf("%d:%d: %s", issue.Position.Line, issue.Position.Column, issue.Check))
+	}
+	expected := []string{
+		"13:6: " + astra.CheckDuplicateName,
+		"14:7: " + astra.CheckDuplicateName,
+		"16:38: " + astra.CheckDuplicateKey,
+		"17:20: " + astra.CheckNamingStyle,
+		"20:20: " + astra.CheckUnexportedField,
+		"21:37: " + astra.CheckMalformedTag,
+		"25:11: " + astra.CheckDuplicateName,
+		"25:11: " + astra.CheckDuplicateName,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected issues\n%v\nfound\n%v", expected, actual)
+	}
+}
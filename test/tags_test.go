@@ -0,0 +1,28 @@
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+)
+
+func TestTagDiagnostics(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "tags", source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = file.DiagnosticErrors()
+	expected := filepath.Join(assetsDir, "tags", source) + ":7:34: malformed tag of field Broken: bad syntax for struct tag value"
+	if err == nil || !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("expected %q, found %v", expected, err)
+	}
+	fields := file.Structures[0].Fields
+	if tag := fields[1].Tag("json"); tag == nil || tag.Name != "a" || !tag.HasOption("omitempty") {
+		t.Errorf("second name of field has wrong tag: %v", tag)
+	}
+	if fields[3].TagList != nil {
+		t.Errorf("field without tags has tags: %v", fields[3].TagList)
+	}
+}
@@ -0,0 +1,11 @@
+module example.com/app
+
+go 1.21
+
+require (
+	example.com/app/nested v0.0.0
+	example.com/ext v1.2.0
+	example.com/lib v1.0.0
+)
+
+replace example.com/app/nested => ./nested
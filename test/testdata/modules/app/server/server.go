@@ -0,0 +1,13 @@
+package server
+
+import (
+	"example.com/app/nested/inner"
+	"example.com/ext"
+	"example.com/lib/util"
+)
+
+type Server struct {
+	Helper util.Helper
+	Inner  inner.Inner
+	Option extension.Option
+}
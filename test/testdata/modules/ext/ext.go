@@ -0,0 +1,3 @@
+package extension
+
+type Option struct{}
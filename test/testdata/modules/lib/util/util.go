@@ -0,0 +1,5 @@
+package util
+
+type Helper struct {
+	Name string
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+func TestCheckTypes(t *testing.T) {
+	file, err := astra.ParseFile(filepath.Join(assetsDir, "typecheck", source), astra.CheckTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]types.TypeInfo{
+		"VarA":     {Type: "int", Underlying: "int"},
+		"VarB":     {Type: "github.com/vetcher/go-astra/test/assets/full/thisisstubpackage.ThisIsStubStructure", Underlying: "struct{ThisIsStubField interface{}}", Package: "github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"},
+		"FieldOne": {Type: "io.Reader", Underlying: "interface{Read(p []byte) (n int, err error)}", Package: "io"},
+		"FieldTwo": {Type: "[]*github.com/vetcher/go-astra/test/assets/full/thisisstubpackage.ThisIsStubStructure", Underlying: "[]*github.com/vetcher/go-astra/test/assets/full/thisisstubpackage.ThisIsStubStructure", Package: "github.com/vetcher/go-astra/test/assets/full/thisisstubpackage"},
+		"Value":    {Type: "T", Underlying: "interface{}", IsTypeParam: true},
+		"MyInt":    {Type: "MyInt", Underlying: "int", Package: "github.com/vetcher/go-astra/test/assets/typecheck"},
+	}
+	actual := map[string]*types.TypeInfo{}
+	for _, v := range file.Vars {
+		actual[v.Name] = v.TypeInfo
+	}
+	for _, s := range file.Structures {
+		for _, f := range s.Fields {
+			actual[f.Name] = f.TypeInfo
+		}
+	}
+	for _, tp := range file.Types {
+		actual[tp.Name] = tp.TypeInfo
+	}
+	for name, exp := range expected {
+		info := actual[name]
+		if info == nil {
+			t.Errorf("%s: type info is empty", name)
+			continue
+		}
+		if info.Object == nil {
+			t.Errorf("%s: object is empty", name)
+		}
+		info.Object = nil
+		if *info != exp {
+			t.Errorf("%s: expected %+v, found %+v", name, exp, *info)
+		}
+	}
+}
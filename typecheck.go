@@ -0,0 +1,260 @@
+package astra
+
+import (
+	"fmt"
+	"go/ast"
+	astparser "go/parser"
+	"go/token"
+	gotypes "go/types"
+	"path/filepath"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+// sourceImporter type-checks imported packages from sources, that are found by Resolver.
+// It is not safe for concurrent use, Resolver guards it with checkMx.
+type sourceImporter struct {
+	resolver *Resolver
+	fset     *token.FileSet
+	packages map[string]*gotypes.Package
+}
+
+func newSourceImporter(r *Resolver) *sourceImporter {
+	return &sourceImporter{
+		resolver: r,
+		fset:     r.fset,
+		packages: map[string]*gotypes.Package{"unsafe": gotypes.Unsafe},
+	}
+}
+
+func (i *sourceImporter) Import(path string) (*gotypes.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+func (i *sourceImporter) ImportFrom(path, _ string, _ gotypes.ImportMode) (*gotypes.Package, error) {
+	if pkg, ok := i.packages[path]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle via %s", path)
+		}
+		return pkg, nil
+	}
+	i.packages[path] = nil // mark as in progress
+	dir, err := i.resolver.PackageDir(path)
+	if err != nil {
+		delete(i.packages, path)
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	files, err := parseCheckedFiles(i.fset, i.resolver.overlay, dir, 0)
+	if err != nil {
+		delete(i.packages, path)
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	conf := gotypes.Config{
+		Importer:         i,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {}, // collect as much information as possible
+	}
+	pkg, _ := conf.Check(path, i.fset, files, nil)
+	i.packages[path] = pkg
+	return pkg, nil
+}
+
+// Parses all non-test .go files from directory, which satisfy build constraints.
+func parseCheckedFiles(fset *token.FileSet, ov overlay, dir string, mode astparser.Mode) ([]*ast.File, error) {
+	pkgs, err := parseDir(diskFS{overlay: ov}, fset, dir, sourceFilter(ov, dir), mode, 0)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse dir: %v", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("unexpected number of packages: expect 1, found %d", len(pkgs))
+	}
+	for _, pkg := range pkgs {
+		return packageFiles(pkg), nil
+	}
+	return nil, nil
+}
+
+// Check type-checks files of one package with imports, loaded from sources.
+// Type errors do not stop checking, the first of them is returned with partially filled package.
+func (r *Resolver) Check(fset *token.FileSet, files []*ast.File) (*gotypes.Package, error) {
+	r.checkMx.Lock()
+	defer r.checkMx.Unlock()
+	if r.importer == nil {
+		r.importer = newSourceImporter(r)
+	}
+	var firstErr error
+	conf := gotypes.Config{
+		Importer:    r.importer,
+		FakeImportC: true,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(r.importPath(), fset, files, nil)
+	return pkg, firstErr
+}
+
+// Annotates all top-level variables, constants, types, struct fields, function and method arguments of file
+// with information from type-checked package.
+// Entities are matched by their names, so file should be parsed from the same sources as pkg.
+func AnnotateTypes(file *types.File, pkg *gotypes.Package) {
+	if file == nil || pkg == nil {
+		return
+	}
+	qf := gotypes.RelativeTo(pkg)
+	scope := pkg.Scope()
+	for i := range file.Constants {
+		annotateVariable(&file.Constants[i], scope.Lookup(file.Constants[i].Name), qf)
+	}
+	for i := range file.Vars {
+		annotateVariable(&file.Vars[i], scope.Lookup(file.Vars[i].Name), qf)
+	}
+	for i := range file.Types {
+		obj := scope.Lookup(file.Types[i].Name)
+		if obj != nil {
+			file.Types[i].TypeInfo = typeInfoOf(obj, qf)
+		}
+	}
+	for i := range file.Structures {
+		obj := scope.Lookup(file.Structures[i].Name)
+		if obj == nil {
+			continue
+		}
+		st, ok := obj.Type().Underlying().(*gotypes.Struct)
+		if !ok || st.NumFields() != len(file.Structures[i].Fields) {
+			continue
+		}
+		for j := range file.Structures[i].Fields {
+			annotateVariable(&file.Structures[i].Fields[j].Variable, st.Field(j), qf)
+		}
+	}
+	for i := range file.Interfaces {
+		obj := scope.Lookup(file.Interfaces[i].Name)
+		if obj == nil {
+			continue
+		}
+		iface, ok := obj.Type().Underlying().(*gotypes.Interface)
+		if !ok {
+			continue
+		}
+		for _, m := range file.Interfaces[i].Methods {
+			for j := 0; j < iface.NumMethods(); j++ {
+				if iface.Method(j).Name() == m.Name {
+					annotateFunction(m, iface.Method(j), qf)
+				}
+			}
+		}
+	}
+	for i := range file.Functions {
+		if fn, ok := scope.Lookup(file.Functions[i].Name).(*gotypes.Func); ok {
+			annotateFunction(&file.Functions[i], fn, qf)
+		}
+	}
+	for i := range file.Methods {
+		recv := types.TypeName(file.Methods[i].Receiver.Type)
+		if recv == nil {
+			continue
+		}
+		obj := scope.Lookup(*recv)
+		if obj == nil {
+			continue
+		}
+		fn, _, _ := gotypes.LookupFieldOrMethod(gotypes.NewPointer(obj.Type()), false, pkg, file.Methods[i].Name)
+		if fn, ok := fn.(*gotypes.Func); ok {
+			annotateFunction(&file.Methods[i].Function, fn, qf)
+			annotateVariable(&file.Methods[i].Receiver, fn.Type().(*gotypes.Signature).Recv(), qf)
+		}
+	}
+}
+
+func annotateFunction(fn *types.Function, obj *gotypes.Func, qf gotypes.Qualifier) {
+	sig, ok := obj.Type().(*gotypes.Signature)
+	if !ok {
+		return
+	}
+	if sig.Params().Len() == len(fn.Args) {
+		for i := range fn.Args {
+			annotateVariable(&fn.Args[i], sig.Params().At(i), qf)
+		}
+	}
+	if sig.Results().Len() == len(fn.Results) {
+		for i := range fn.Results {
+			annotateVariable(&fn.Results[i], sig.Results().At(i), qf)
+		}
+	}
+}
+
+func annotateVariable(v *types.Variable, obj gotypes.Object, qf gotypes.Qualifier) {
+	if obj == nil {
+		return
+	}
+	v.TypeInfo = typeInfoOf(obj, qf)
+}
+
+func typeInfoOf(obj gotypes.Object, qf gotypes.Qualifier) *types.TypeInfo {
+	info := &types.TypeInfo{
+		Object: obj,
+	}
+	t := obj.Type()
+	if t == nil {
+		return info
+	}
+	info.Type = gotypes.TypeString(t, qf)
+	info.Underlying = gotypes.TypeString(t.Underlying(), qf)
+	switch x := gotypes.Unalias(elemType(t)).(type) {
+	case *gotypes.Named:
+		if x.Obj().Pkg() != nil {
+			info.Package = x.Obj().Pkg().Path()
+		}
+	case *gotypes.TypeParam:
+		info.IsTypeParam = true
+	}
+	return info
+}
+
+// Removes pointers, slices, arrays and channels from type.
+func elemType(t gotypes.Type) gotypes.Type {
+	for {
+		switch x := gotypes.Unalias(t).(type) {
+		case *gotypes.Pointer:
+			t = x.Elem()
+		case *gotypes.Slice:
+			t = x.Elem()
+		case *gotypes.Array:
+			t = x.Elem()
+		case *gotypes.Chan:
+			t = x.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// Type-checks parsed files with new Resolver for dir, which reads files from overlay, and annotates file.
+func checkAndAnnotate(file *types.File, fset *token.FileSet, files []*ast.File, dir string, ov overlay, options ...Option) error {
+	resolver, err := newResolver(dir, ov, options...)
+	if err != nil {
+		return err
+	}
+	return resolver.checkAndAnnotate(file, fset, files)
+}
+
+func (r *Resolver) checkAndAnnotate(file *types.File, fset *token.FileSet, files []*ast.File) error {
+	pkg, err := r.Check(fset, files)
+	if pkg == nil {
+		return fmt.Errorf("can not check types: %v", err)
+	}
+	AnnotateTypes(file, pkg)
+	return nil
+}
+
+// Best effort guess of import path of resolver's directory.
+func (r *Resolver) importPath() string {
+	if path, err := importPath(r.overlay, r.dir); err == nil {
+		return path
+	}
+	return filepath.Base(r.dir)
+}
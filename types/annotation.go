@@ -0,0 +1,42 @@
+package types
+
+import "go/token"
+
+// Annotation is a DSL instruction from documentation, like `// @http method=GET path=/users/{id}`.
+type Annotation struct {
+	Name   string            `json:"name"`             // Name after prefix, `http` in example.
+	Values []string          `json:"values,omitempty"` // Positional values: `@microgen middleware, logging`.
+	Params []AnnotationParam `json:"params,omitempty"` // Named values in order of declaration: `method=GET`.
+	Raw    string            `json:"raw"`              // Line of the comment, which contains annotation.
+	Pos    token.Pos         `json:"-"`                // Position of the annotation in the FileSet, which was used for parsing.
+}
+
+// AnnotationParam is a `key=value` or `key=[value1, value2]` parameter of annotation.
+type AnnotationParam struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values,omitempty"`
+}
+
+// Param returns first value of the parameter and true, if parameter exists.
+func (a Annotation) Param(key string) (string, bool) {
+	for _, p := range a.Params {
+		if p.Key == key {
+			if len(p.Values) == 0 {
+				return "", true
+			}
+			return p.Values[0], true
+		}
+	}
+	return "", false
+}
+
+// Returns all annotations with provided name.
+func FilterAnnotations(annotations []Annotation, name string) []Annotation {
+	var result []Annotation
+	for i := range annotations {
+		if annotations[i].Name == name {
+			result = append(result, annotations[i])
+		}
+	}
+	return result
+}
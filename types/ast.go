@@ -0,0 +1,446 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// ToAstExpr converts type to go/ast expression.
+// Imported types are qualified with names of their imports.
+func ToAstExpr(t Type) ast.Expr {
+	return File{}.AstExpr(t)
+}
+
+// AstExpr converts type to go/ast expression.
+// Imported types are qualified with aliases of the same packages from f.Imports,
+// so types from other files or converted from go/types are printed with names, that are valid in f.
+func (f File) AstExpr(t Type) ast.Expr {
+	switch x := t.(type) {
+	case nil:
+		return nil
+	case TName:
+		return withTypeArgs(ast.NewIdent(x.TypeName), f.astExprs(x.TypeArgs))
+	case TImport:
+		next := f.AstExpr(x.Next)
+		name := f.importName(x.Import)
+		if name == "" {
+			return next
+		}
+		switch n := next.(type) {
+		case *ast.Ident:
+			return &ast.SelectorExpr{X: ast.NewIdent(name), Sel: n}
+		case *ast.IndexExpr:
+			n.X = &ast.SelectorExpr{X: ast.NewIdent(name), Sel: n.X.(*ast.Ident)}
+			return n
+		case *ast.IndexListExpr:
+			n.X = &ast.SelectorExpr{X: ast.NewIdent(name), Sel: n.X.(*ast.Ident)}
+			return n
+		}
+		return next
+	case TPointer:
+		expr := f.AstExpr(x.Next)
+		for i := 0; i < x.NumberOfPointers; i++ {
+			expr = &ast.StarExpr{X: expr}
+		}
+		return expr
+	case TArray:
+		switch {
+		case x.IsSlice:
+			return &ast.ArrayType{Elt: f.AstExpr(x.Next)}
+		case x.IsEllipsis:
+			return &ast.ArrayType{Len: &ast.Ellipsis{}, Elt: f.AstExpr(x.Next)}
+		case x.LenExpr != "":
+			return &ast.ArrayType{Len: lenExpr(x.LenExpr), Elt: f.AstExpr(x.Next)}
+		default:
+			return &ast.ArrayType{Len: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(x.ArrayLen)}, Elt: f.AstExpr(x.Next)}
+		}
+	case TEllipsis:
+		return &ast.Ellipsis{Elt: f.AstExpr(x.Next)}
+	case TMap:
+		return &ast.MapType{Key: f.AstExpr(x.Key), Value: f.AstExpr(x.Value)}
+	case TChan:
+		dir := ast.ChanDir(x.Direction)
+		if dir == 0 {
+			dir = ast.SEND | ast.RECV
+		}
+		return &ast.ChanType{Dir: dir, Value: f.AstExpr(x.Next)}
+	case TInterface:
+		if x.Interface == nil {
+			return &ast.InterfaceType{Methods: &ast.FieldList{}}
+		}
+		return f.InterfaceType(*x.Interface)
+	case TUnion:
+		var expr ast.Expr
+		for _, term := range x.Terms {
+			var next ast.Expr = f.AstExpr(term.Type)
+			if term.Tilde {
+				next = &ast.UnaryExpr{Op: token.TILDE, X: next}
+			}
+			if expr == nil {
+				expr = next
+			} else {
+				expr = &ast.BinaryExpr{X: expr, Op: token.OR, Y: next}
+			}
+		}
+		return expr
+	case Struct:
+		return f.structType(x)
+	case *Struct:
+		return f.structType(*x)
+	case Function:
+		return f.funcType(x)
+	case *Function:
+		return f.funcType(*x)
+	}
+	return nil
+}
+
+// AstFile converts declarations to go/ast file of package f.Name, which imports packages, required by declarations.
+// Structures, interfaces and types are converted to type declarations, functions and methods to declarations without bodies.
+// Nodes of the file have positions in returned FileSet, which should be used for printing,
+// so documentation is placed on own lines before declarations, fields and methods, as gofmt places it.
+func (f File) AstFile(decls ...Decl) (*token.FileSet, *ast.File, error) {
+	file := &ast.File{Name: ast.NewIdent(f.Name)}
+	if specs := f.importSpecs(decls); len(specs) > 0 {
+		file.Decls = append(file.Decls, &ast.GenDecl{Tok: token.IMPORT, Specs: specs})
+	}
+	for _, decl := range decls {
+		d, err := f.astDecl(decl)
+		if err != nil {
+			return nil, nil, err
+		}
+		file.Decls = append(file.Decls, d)
+	}
+	return positioned(file)
+}
+
+func (f File) astDecl(decl Decl) (ast.Decl, error) {
+	switch d := decl.(type) {
+	case Struct:
+		return typeDecl(f.StructSpec(d)), nil
+	case Interface:
+		return typeDecl(&ast.TypeSpec{
+			Doc:        astComments(d.Docs),
+			Name:       ast.NewIdent(d.Name),
+			TypeParams: f.fieldList(d.TypeParams),
+			Type:       f.InterfaceType(d),
+		}), nil
+	case FileType:
+		return typeDecl(&ast.TypeSpec{
+			Doc:        astComments(d.Docs),
+			Name:       ast.NewIdent(d.Name),
+			TypeParams: f.fieldList(d.TypeParams),
+			Type:       f.AstExpr(d.Type),
+		}), nil
+	case Method:
+		return f.MethodDecl(d), nil
+	case Function:
+		return f.FuncDecl(d), nil
+	case *Struct:
+		if d != nil {
+			return f.astDecl(*d)
+		}
+	case *Interface:
+		if d != nil {
+			return f.astDecl(*d)
+		}
+	case *FileType:
+		if d != nil {
+			return f.astDecl(*d)
+		}
+	case *Method:
+		if d != nil {
+			return f.astDecl(*d)
+		}
+	case *Function:
+		if d != nil {
+			return f.astDecl(*d)
+		}
+	}
+	return nil, fmt.Errorf("can not convert %T to declaration", decl)
+}
+
+// Wraps type specification to declaration. Documentation of not grouped specification belongs to declaration,
+// as parser places it.
+func typeDecl(spec *ast.TypeSpec) *ast.GenDecl {
+	decl := &ast.GenDecl{Doc: spec.Doc, Tok: token.TYPE, Specs: []ast.Spec{spec}}
+	spec.Doc = nil
+	return decl
+}
+
+// Returns specifications of imports, which are required by declarations, with names, that AstExpr uses for them.
+func (f File) importSpecs(decls []Decl) []ast.Spec {
+	var (
+		specs []ast.Spec
+		seen  = make(map[string]bool)
+	)
+	for _, imp := range f.RequiredImports(decls...) {
+		imp = f.fileImport(imp)
+		key := imp.Name + " " + imp.Package
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(imp.Package)}}
+		switch {
+		case imp.Kind == ImportDot:
+			spec.Name = ast.NewIdent(".")
+		case imp.LocalName() != imp.RealName:
+			spec.Name = ast.NewIdent(imp.LocalName())
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// Returns copy of file, which nodes have positions in new FileSet. File is printed without documentation and parsed back,
+// then documentation is inserted before documented nodes, declarations are separated with empty lines
+// and source is parsed again.
+func positioned(file *ast.File) (*token.FileSet, *ast.File, error) {
+	var docs [][]string
+	walkDocs(file, func(doc **ast.CommentGroup, _ token.Pos) {
+		var lines []string
+		if *doc != nil {
+			for _, c := range (*doc).List {
+				lines = append(lines, c.Text)
+			}
+		}
+		docs = append(docs, lines)
+		*doc = nil
+	})
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), file); err != nil {
+		return nil, nil, fmt.Errorf("can not print file: %v", err)
+	}
+	src := buf.Bytes()
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not parse printed file: %v", err)
+	}
+	var offsets []int
+	walkDocs(parsed, func(_ **ast.CommentGroup, pos token.Pos) {
+		offsets = append(offsets, fset.Position(pos).Offset)
+	})
+	if len(offsets) != len(docs) {
+		return nil, nil, fmt.Errorf("printed file does not match declarations: %d documented nodes, expect %d", len(offsets), len(docs))
+	}
+	separated := make(map[int]bool)
+	for _, decl := range parsed.Decls[1:] {
+		separated[fset.Position(decl.Pos()).Offset] = true
+	}
+	for i := len(offsets) - 1; i >= 0; i-- {
+		src = insertDoc(src, offsets[i], docs[i], separated[offsets[i]])
+	}
+	fset = token.NewFileSet()
+	parsed, err = parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not parse documented file: %v", err)
+	}
+	return fset, parsed, nil
+}
+
+// Calls fn for documentation and position of every declaration, type specification and field in order of the source.
+func walkDocs(node ast.Node, fn func(doc **ast.CommentGroup, pos token.Pos)) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.GenDecl:
+			fn(&x.Doc, x.Pos())
+		case *ast.FuncDecl:
+			fn(&x.Doc, x.Pos())
+		case *ast.TypeSpec:
+			fn(&x.Doc, x.Pos())
+		case *ast.Field:
+			fn(&x.Doc, x.Pos())
+		}
+		return true
+	})
+}
+
+// Inserts documentation before node at offset of src. Node, which starts its line, gets documentation with the same indent,
+// otherwise documentation is placed on new lines in the middle of the line, e.g. before parameter of function.
+// Separated node gets empty line before documentation, when previous line is not empty.
+func insertDoc(src []byte, offset int, docs []string, separated bool) []byte {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	indent := src[start:offset]
+	var text bytes.Buffer
+	if separated && start >= 2 && src[start-2] != '\n' {
+		text.WriteByte('\n')
+	}
+	if len(docs) > 0 && len(bytes.TrimSpace(indent)) > 0 {
+		start, indent = offset, nil
+		text.WriteByte('\n')
+	}
+	for _, doc := range docs {
+		text.Write(indent)
+		text.WriteString(doc)
+		text.WriteByte('\n')
+	}
+	result := make([]byte, 0, len(src)+text.Len())
+	result = append(result, src[:start]...)
+	result = append(result, text.Bytes()...)
+	return append(result, src[start:]...)
+}
+
+// FuncDecl converts function to declaration without body.
+// Documentation has no positions, so it is printed on own lines only as a part of AstFile.
+func (f File) FuncDecl(fn Function) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Doc:  astComments(fn.Docs),
+		Name: ast.NewIdent(fn.Name),
+		Type: f.funcType(fn),
+	}
+}
+
+// MethodDecl converts method to declaration without body.
+func (f File) MethodDecl(m Method) *ast.FuncDecl {
+	decl := f.FuncDecl(m.Function)
+	decl.Recv = f.fieldList([]Variable{m.Receiver})
+	return decl
+}
+
+// StructSpec converts structure to type specification with field tags and docs.
+// Documentation has no positions, so it is printed on own lines only as a part of AstFile.
+func (f File) StructSpec(s Struct) *ast.TypeSpec {
+	return &ast.TypeSpec{
+		Doc:        astComments(s.Docs),
+		Name:       ast.NewIdent(s.Name),
+		TypeParams: f.fieldList(s.TypeParams),
+		Type:       f.structType(s),
+	}
+}
+
+// InterfaceType converts interface methods and embedded interfaces to interface type.
+func (f File) InterfaceType(i Interface) *ast.InterfaceType {
+	methods := &ast.FieldList{}
+	for _, m := range i.Methods {
+		if m == nil {
+			continue
+		}
+		methods.List = append(methods.List, &ast.Field{
+			Doc:   astComments(m.Docs),
+			Names: []*ast.Ident{ast.NewIdent(m.Name)},
+			Type:  f.funcType(*m),
+		})
+	}
+	for _, embedded := range i.Interfaces {
+		methods.List = append(methods.List, &ast.Field{
+			Doc:  astComments(embedded.Docs),
+			Type: f.AstExpr(embedded.Type),
+		})
+	}
+	return &ast.InterfaceType{Methods: methods}
+}
+
+func (f File) importName(imp *Import) string {
+	if imp == nil {
+		return ""
+	}
+	return f.fileImport(imp).LocalName()
+}
+
+// Returns import of f with the same package, as imp, or imp itself, when f does not import the package.
+func (f File) fileImport(imp *Import) *Import {
+	for _, fileImp := range f.Imports {
+		if fileImp != nil && fileImp.Package == imp.Package && fileImp.Kind != ImportBlank {
+			return fileImp
+		}
+	}
+	return imp
+}
+
+func (f File) astExprs(ts []Type) []ast.Expr {
+	exprs := make([]ast.Expr, len(ts))
+	for i := range ts {
+		exprs[i] = f.AstExpr(ts[i])
+	}
+	return exprs
+}
+
+func withTypeArgs(x ast.Expr, args []ast.Expr) ast.Expr {
+	switch len(args) {
+	case 0:
+		return x
+	case 1:
+		return &ast.IndexExpr{X: x, Index: args[0]}
+	default:
+		return &ast.IndexListExpr{X: x, Indices: args}
+	}
+}
+
+func (f File) funcType(fn Function) *ast.FuncType {
+	t := &ast.FuncType{
+		TypeParams: f.fieldList(fn.TypeParams),
+		Params:     f.fieldList(fn.Args),
+		Results:    f.fieldList(fn.Results),
+	}
+	if t.Params == nil {
+		t.Params = &ast.FieldList{}
+	}
+	return t
+}
+
+func (f File) fieldList(vars []Variable) *ast.FieldList {
+	if len(vars) == 0 {
+		return nil
+	}
+	list := &ast.FieldList{}
+	for _, v := range vars {
+		field := &ast.Field{
+			Doc:  astComments(v.Docs),
+			Type: f.AstExpr(v.Type),
+		}
+		if v.Name != "" {
+			field.Names = []*ast.Ident{ast.NewIdent(v.Name)}
+		}
+		list.List = append(list.List, field)
+	}
+	return list
+}
+
+func (f File) structType(s Struct) *ast.StructType {
+	fields := &ast.FieldList{}
+	for _, sf := range s.Fields {
+		field := &ast.Field{
+			Doc:  astComments(sf.Docs),
+			Type: f.AstExpr(sf.Type),
+		}
+		if sf.Name != "" {
+			field.Names = []*ast.Ident{ast.NewIdent(sf.Name)}
+		}
+		if sf.RawTags != "" {
+			tag := sf.RawTags
+			if !strings.HasPrefix(tag, "`") && !strings.HasPrefix(tag, `"`) {
+				tag = "`" + tag + "`"
+			}
+			field.Tag = &ast.BasicLit{Kind: token.STRING, Value: tag}
+		}
+		fields.List = append(fields.List, field)
+	}
+	return &ast.StructType{Fields: fields}
+}
+
+func astComments(docs []string) *ast.CommentGroup {
+	if len(docs) == 0 {
+		return nil
+	}
+	group := &ast.CommentGroup{}
+	for _, doc := range docs {
+		group.List = append(group.List, &ast.Comment{Text: doc})
+	}
+	return group
+}
+
+// Returns expression of array length. Expressions, which can not be parsed, are kept as identifiers to be printed as is.
+func lenExpr(expr string) ast.Expr {
+	if x, err := parser.ParseExpr(expr); err == nil {
+		return x
+	}
+	return ast.NewIdent(expr)
+}
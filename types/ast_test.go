@@ -0,0 +1,156 @@
+package types
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"testing"
+)
+
+func TestAstConverters(test *testing.T) {
+	file := File{
+		Imports: []*Import{
+			{Base: Base{Name: "pkgalias"}, Package: "example.com/pkg"},
+		},
+	}
+	foreign := &Import{Base: Base{Name: "pkg"}, Package: "example.com/pkg"}
+	thing := TImport{Import: foreign, Next: TName{TypeName: "Thing"}}
+	tt := []struct {
+		Name   string
+		Node   func() interface{}
+		Result string
+	}{
+		{
+			Name: "Type map",
+			Node: func() interface{} {
+				return ToAstExpr(TMap{Key: TName{TypeName: "string"}, Value: TPointer{NumberOfPointers: 2, Next: thing}})
+			},
+			Result: "map[string]**pkg.Thing",
+		},
+		{
+			Name:   "Type with file alias",
+			Node:   func() interface{} { return file.AstExpr(TArray{IsSlice: true, Next: thing}) },
+			Result: "[]pkgalias.Thing",
+		},
+		{
+			Name:   "Array with constant length",
+			Node:   func() interface{} { return ToAstExpr(TArray{LenExpr: "2 * N", Next: TName{TypeName: "byte"}}) },
+			Result: "[2 * N]byte",
+		},
+		{
+			Name: "Generic type",
+			Node: func() interface{} {
+				return file.AstExpr(TImport{Import: foreign, Next: TName{TypeName: "Map", TypeArgs: []Type{TName{TypeName: "string"}, TChan{Direction: ChanDirRecv, Next: TName{TypeName: "int"}}}}})
+			},
+			Result: "pkgalias.Map[string, <-chan int]",
+		},
+		{
+			Name: "Function",
+			Node: func() interface{} {
+				return file.FuncDecl(Function{
+					Base: Base{Name: "Do"},
+					Args: []Variable{
+						{Base: Base{Name: "a"}, Type: thing},
+						{Base: Base{Name: "b"}, Type: TEllipsis{Next: TName{TypeName: "string"}}},
+					},
+					Results: []Variable{{Type: TName{TypeName: "error"}}},
+				})
+			},
+			Result: "func Do(a pkgalias.Thing, b ...string) error",
+		},
+		{
+			Name: "Struct",
+			Node: func() interface{} {
+				return file.StructSpec(Struct{
+					Base: Base{Name: "S"},
+					Fields: []StructField{
+						{Variable: Variable{Base: Base{Name: "A"}, Type: TName{TypeName: "int"}}, RawTags: "`json:\"a\"`"},
+						{Variable: Variable{Type: thing}},
+					},
+				})
+			},
+			Result: "S struct {\n\tA int `json:\"a\"`\n\tpkgalias.Thing\n}",
+		},
+		{
+			Name: "Interface",
+			Node: func() interface{} {
+				return file.InterfaceType(Interface{
+					Methods:    []*Function{{Base: Base{Name: "M"}, Args: []Variable{{Type: TName{TypeName: "int"}}}}},
+					Interfaces: []Variable{{Type: TUnion{Terms: []TTerm{{Tilde: true, Type: TName{TypeName: "int"}}, {Type: TName{TypeName: "string"}}}}}},
+				})
+			},
+			Result: "interface {\n\tM(int)\n\t~int | string\n}",
+		},
+	}
+	for _, t := range tt {
+		test.Run(t.Name, func(test *testing.T) {
+			var buf bytes.Buffer
+			err := format.Node(&buf, token.NewFileSet(), t.Node())
+			if err != nil {
+				test.Fatal(err)
+			}
+			if t.Result != buf.String() {
+				test.Error("has", buf.String(), "want", t.Result)
+			}
+		})
+	}
+}
+
+func TestAstComments(test *testing.T) {
+	context := &Import{Base: Base{Name: "ctx"}, Package: "context", Kind: ImportAliased, RealName: "context"}
+	file := File{Base: Base{Name: "pkg"}, Imports: []*Import{context}}
+	fset, node, err := file.AstFile(
+		Struct{
+			Base: Base{Name: "S", Docs: []string{"// S is a structure."}},
+			Fields: []StructField{
+				{Variable: Variable{Base: Base{Name: "A", Docs: []string{"// A is a field."}}, Type: TName{TypeName: "int"}}},
+				{Variable: Variable{Base: Base{Name: "B", Docs: []string{"// B is a field.", "// Second line."}}, Type: TName{TypeName: "int"}}},
+				{Variable: Variable{Base: Base{Name: "C"}, Type: TChan{Next: TName{TypeName: "int"}}}},
+			},
+		},
+		&Interface{
+			Base:    Base{Name: "I"},
+			Methods: []*Function{{Base: Base{Name: "M", Docs: []string{"// M is a method."}}}},
+		},
+		Function{
+			Base: Base{Name: "F", Docs: []string{"// F does."}},
+			Args: []Variable{{Base: Base{Name: "c"}, Type: TImport{Import: context, Next: TName{TypeName: "Context"}}}},
+		},
+	)
+	if err != nil {
+		test.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		test.Fatal(err)
+	}
+	expected := `package pkg
+
+import ctx "context"
+
+// S is a structure.
+type S struct {
+	// A is a field.
+	A int
+	// B is a field.
+	// Second line.
+	B int
+	C chan int
+}
+
+type I interface {
+	// M is a method.
+	M()
+}
+
+// F does.
+func F(c ctx.Context)
+`
+	if buf.String() != expected {
+		test.Errorf("has\n%s\nwant\n%s", buf.String(), expected)
+	}
+	if node.Decls[1].(*ast.GenDecl).Doc.Text() != "S is a structure.\n" {
+		test.Error("documentation should be attached to declaration")
+	}
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Comments separates comments of the entity by their place in the source.
+type Comments struct {
+	Group    *Comment `json:"group,omitempty"`    // Documentation of declarations group, e.g. above `import (` or `type (`.
+	Doc      *Comment `json:"doc,omitempty"`      // Own documentation of the entity.
+	Trailing *Comment `json:"trailing,omitempty"` // Inline comment after the entity on the same line.
+}
+
+// Comment is a group of comments without empty lines between them.
+type Comment struct {
+	Raw       []string    `json:"raw,omitempty"` // Comments as they are in the source, with `//` and `/* */` markers.
+	Positions []token.Pos `json:"-"`             // Positions of Raw comments in the FileSet, which was used for parsing.
+}
+
+// Text returns text of comment without comment markers, leading and trailing empty lines.
+// Lines of the text are separated by '\n'. Directives, like `//go:generate`, are not included.
+func (c *Comment) Text() string {
+	if c == nil {
+		return ""
+	}
+	group := &ast.CommentGroup{}
+	for _, raw := range c.Raw {
+		group.List = append(group.List, &ast.Comment{Text: raw})
+	}
+	return strings.TrimSuffix(group.Text(), "\n")
+}
+
+// Text returns cleaned documentation of the entity, group documentation is used, when entity has no own.
+func (c *Comments) Text() string {
+	if c == nil {
+		return ""
+	}
+	if c.Doc != nil {
+		return c.Doc.Text()
+	}
+	return c.Group.Text()
+}
+
+// Returns all not nil comments in order: group, doc, trailing.
+func (c *Comments) List() []*Comment {
+	if c == nil {
+		return nil
+	}
+	var list []*Comment
+	for _, comment := range []*Comment{c.Group, c.Doc, c.Trailing} {
+		if comment != nil {
+			list = append(list, comment)
+		}
+	}
+	return list
+}
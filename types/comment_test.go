@@ -0,0 +1,24 @@
+package types
+
+import "testing"
+
+func TestCommentText(test *testing.T) {
+	tt := []struct {
+		Name    string
+		Comment *Comment
+		Result  string
+	}{
+		{Name: "Nil comment", Comment: nil, Result: ""},
+		{Name: "Line comments", Comment: &Comment{Raw: []string{"// First line.", "//", "// Second line."}}, Result: "First line.\n\nSecond line."},
+		{Name: "Block comment", Comment: &Comment{Raw: []string{"/*\n\tMulti-line\n\tdocumentation.\n*/"}}, Result: "\tMulti-line\n\tdocumentation."},
+		{Name: "Directive", Comment: &Comment{Raw: []string{"// Doc.", "//go:generate echo"}}, Result: "Doc."},
+	}
+	for _, t := range tt {
+		test.Run(t.Name, func(test *testing.T) {
+			s := t.Comment.Text()
+			if t.Result != s {
+				test.Errorf("has %q want %q", s, t.Result)
+			}
+		})
+	}
+}
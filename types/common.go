@@ -4,7 +4,15 @@ package types
 // It contains name of entity and docs.
 // Docs is a comments in golang syntax above entity declaration.
 // Each block comment is counted as one.
+// Comments contains the same comments, separated by their place in the source.
+// Directives are parsed from the same comments, even when comments are ignored.
+// Annotations are filled only by astra.AnnotationParser.
+// Deprecated is true, when documentation contains `Deprecated: ` paragraph.
 type Base struct {
-	Name string   `json:"name,omitempty"`
-	Docs []string `json:"docs,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Docs        []string     `json:"docs,omitempty"`
+	Comments    *Comments    `json:"comments,omitempty"`
+	Directives  []Directive  `json:"directives,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
 }
@@ -0,0 +1,13 @@
+package types
+
+// Decl is a top-level declaration of the file.
+// It is one of *Struct, *Interface, *FileType, *Function or *Variable.
+type Decl interface {
+	decl()
+}
+
+func (s Struct) decl()    { return }
+func (i Interface) decl() { return }
+func (t FileType) decl()  { return }
+func (f Function) decl()  { return }
+func (v Variable) decl()  { return }
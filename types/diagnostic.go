@@ -0,0 +1,24 @@
+package types
+
+import (
+	"go/scanner"
+	"go/token"
+)
+
+// Diagnostic is a problem in the source, which does not stop parsing, e.g. malformed struct tag.
+type Diagnostic struct {
+	Message string    `json:"message"`
+	Pos     token.Pos `json:"-"` // Position of problem in the FileSet, which was used for parsing.
+}
+
+// Returns diagnostics of file as scanner.ErrorList with positions, resolved by f.FileSet, or nil, when there are no diagnostics.
+func (f File) DiagnosticErrors() error {
+	var errs scanner.ErrorList
+	for _, d := range f.Diagnostics {
+		errs.Add(f.Position(d.Pos), d.Message)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"go/token"
+	"sort"
+)
+
+// Directive is a special comment, like `//go:generate`, `//go:embed`, `//go:build`, `//nolint` or `// +build`.
+type Directive struct {
+	Name string    `json:"name"`           // Name of directive without `//`, e.g. `go:generate`, `nolint` or `+build`.
+	Args []string  `json:"args,omitempty"` // Arguments of directive, quoted arguments are unquoted.
+	Raw  string    `json:"raw"`            // Directive as it is in the source.
+	Pos  token.Pos `json:"-"`              // Position of directive in the FileSet, which was used for parsing.
+}
+
+const (
+	DirectiveGoGenerate = "go:generate"
+	DirectiveGoEmbed    = "go:embed"
+	DirectiveGoBuild    = "go:build"
+	DirectiveNolint     = "nolint"
+)
+
+// Returns all directives with provided name.
+func FilterDirectives(directives []Directive, name string) []Directive {
+	var result []Directive
+	for i := range directives {
+		if directives[i].Name == name {
+			result = append(result, directives[i])
+		}
+	}
+	return result
+}
+
+// Returns patterns of all `//go:embed` directives of the variable.
+func (v Variable) EmbedPatterns() []string {
+	var patterns []string
+	for _, d := range FilterDirectives(v.Directives, DirectiveGoEmbed) {
+		patterns = append(patterns, d.Args...)
+	}
+	return patterns
+}
+
+// Returns all `//go:generate` directives of the file and its declarations in order of the source,
+// as go generate runs them. Args of each directive is a command with its arguments.
+func (f File) GoGenerate() []Directive {
+	var all []Directive
+	add := func(b Base) {
+		all = append(all, FilterDirectives(b.Directives, DirectiveGoGenerate)...)
+	}
+	add(f.Base)
+	for _, v := range f.Imports {
+		add(v.Base)
+	}
+	for _, v := range f.Constants {
+		add(v.Base)
+	}
+	for _, v := range f.Vars {
+		add(v.Base)
+	}
+	for _, v := range f.Interfaces {
+		add(v.Base)
+	}
+	for _, v := range f.Structures {
+		add(v.Base)
+	}
+	for _, v := range f.Functions {
+		add(v.Base)
+	}
+	for _, v := range f.Methods {
+		add(v.Base)
+	}
+	for _, v := range f.Types {
+		add(v.Base)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Pos < all[j].Pos
+	})
+	return all
+}
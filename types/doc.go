@@ -0,0 +1,111 @@
+package types
+
+import (
+	"go/doc/comment"
+	"strings"
+)
+
+// DocText returns cleaned documentation of the entity.
+// Comments are used, when they exist, otherwise Docs are used.
+func (b Base) DocText() string {
+	if b.Comments != nil {
+		return b.Comments.Text()
+	}
+	return (&Comment{Raw: b.Docs}).Text()
+}
+
+// IsDeprecatedDoc checks, that documentation contains paragraph, which starts with `Deprecated: `.
+func IsDeprecatedDoc(text string) bool {
+	return strings.HasPrefix(text, "Deprecated: ") || strings.Contains(text, "\n\nDeprecated: ")
+}
+
+// DocParser returns go/doc/comment parser, which resolves doc links, like [Name], [Name.Method] and [pkg.Name],
+// against declarations and imports of the file. Packages are found by their local names in the file,
+// dot-imported packages are found by their real names.
+func (f File) DocParser() *comment.Parser {
+	return &comment.Parser{
+		LookupPackage: func(name string) (importPath string, ok bool) {
+			for _, imp := range f.Imports {
+				if imp != nil && imp.LocalName() != "" && imp.LocalName() == name {
+					return imp.Package, true
+				}
+			}
+			// identifiers of dot imports are not qualified, so links refer to them by names of packages
+			for _, imp := range f.Imports {
+				if imp != nil && imp.Kind == ImportDot && imp.RealName == name {
+					return imp.Package, true
+				}
+			}
+			return "", false
+		},
+		LookupSym: func(recv, name string) bool {
+			if recv == "" {
+				return f.FindDecl(name) != nil
+			}
+			return f.hasMember(recv, name)
+		},
+	}
+}
+
+// ParseDoc parses documentation of the entity, declared in the file, to paragraphs, headings, code blocks and lists.
+func (f File) ParseDoc(b Base) *comment.Doc {
+	return f.DocParser().Parse(b.DocText())
+}
+
+// Checks that type typeName has method or field name.
+func (f File) hasMember(typeName, name string) bool {
+	for i := range f.Methods {
+		recv := TypeName(f.Methods[i].Receiver.Type)
+		if recv != nil && *recv == typeName && f.Methods[i].Name == name {
+			return true
+		}
+	}
+	switch d := f.FindDecl(typeName).(type) {
+	case *Struct:
+		for i := range d.Fields {
+			if d.Fields[i].Name == name {
+				return true
+			}
+		}
+	case *Interface:
+		for _, m := range d.Methods {
+			if m != nil && m.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DocLinks returns all doc links of parsed documentation.
+func DocLinks(doc *comment.Doc) []*comment.DocLink {
+	var links []*comment.DocLink
+	var walk func(texts []comment.Text)
+	walk = func(texts []comment.Text) {
+		for _, t := range texts {
+			switch x := t.(type) {
+			case *comment.DocLink:
+				links = append(links, x)
+			case *comment.Link:
+				walk(x.Text)
+			}
+		}
+	}
+	for _, block := range doc.Content {
+		switch b := block.(type) {
+		case *comment.Paragraph:
+			walk(b.Text)
+		case *comment.Heading:
+			walk(b.Text)
+		case *comment.List:
+			for _, item := range b.Items {
+				for _, content := range item.Content {
+					if p, ok := content.(*comment.Paragraph); ok {
+						walk(p.Text)
+					}
+				}
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,51 @@
+package types
+
+import "testing"
+
+func TestParseDoc(test *testing.T) {
+	file := File{
+		Imports: []*Import{
+			{Base: Base{Name: "pkgalias"}, Package: "example.com/pkg"},
+			{Base: Base{Name: "."}, Package: "example.com/dot", Kind: ImportDot, RealName: "dot"},
+			{Base: Base{Name: "_"}, Package: "example.com/blank", Kind: ImportBlank, RealName: "blank"},
+			{Base: Base{Name: "yaml"}, Package: "gopkg.in/yaml.v3", Kind: ImportNormal, RealName: "yaml", Guessed: true},
+		},
+		Structures: []Struct{{
+			Base:   Base{Name: "User"},
+			Fields: []StructField{{Variable: Variable{Base: Base{Name: "ID"}}}},
+		}},
+		Functions: []Function{{Base: Base{
+			Name: "NewUser",
+			Docs: []string{
+				"// NewUser creates [User] with [User.ID], see [pkgalias.Thing], [context.Context] and [Unknown].",
+				"// It uses [dot.Thing], [yaml.Node] and [blank.Thing].",
+				"//",
+				"// Deprecated: use [pkgalias.NewUser] instead.",
+			},
+		}}},
+	}
+	fn := file.Functions[0].Base
+	if !IsDeprecatedDoc(fn.DocText()) {
+		test.Error("function is not deprecated")
+	}
+	if IsDeprecatedDoc("Function is not Deprecated: at all.") {
+		test.Error("deprecation in the middle of paragraph")
+	}
+	doc := file.ParseDoc(fn)
+	if len(doc.Content) != 2 {
+		test.Fatalf("expect 2 paragraphs, found %d", len(doc.Content))
+	}
+	var links []string
+	for _, link := range DocLinks(doc) {
+		links = append(links, link.DefaultURL(""))
+	}
+	expected := []string{"#User", "#User.ID", "/example.com/pkg#Thing", "/context#Context", "/example.com/dot#Thing", "/gopkg.in/yaml.v3#Node", "/example.com/pkg#NewUser"}
+	if len(links) != len(expected) {
+		test.Fatalf("has %v want %v", links, expected)
+	}
+	for i := range links {
+		if links[i] != expected[i] {
+			test.Errorf("has %s want %s", links[i], expected[i])
+		}
+	}
+}
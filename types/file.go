@@ -1,9 +1,13 @@
 package types
 
+import "go/token"
+
 type FileType struct {
 	Base
-	Type    Type      `json:"type,omitempty"`
-	Methods []*Method `json:"methods,omitempty"`
+	TypeParams []Variable `json:"type_params,omitempty"`
+	Type       Type       `json:"type,omitempty"`
+	Methods    []*Method  `json:"methods,omitempty"`
+	TypeInfo   *TypeInfo  `json:"type_info,omitempty"`
 }
 
 // File is a top-level entity, that contains all top-level declarations of the file.
@@ -17,6 +21,24 @@ type File struct {
 	Functions  []Function  `json:"functions,omitempty"`  // Contains `func Foo() {}` declarations.
 	Methods    []Method    `json:"methods,omitempty"`    // Contains `func (a A) Foo(b B) (c C) {}` declarations.
 	Types      []FileType  `json:"types,omitempty"`      // Contains `type X int` declarations.
+
+	FloatingComments []*Comment `json:"floating_comments,omitempty"` // Comments outside of declarations, which are not attached to any of them.
+	License          *Comment   `json:"license,omitempty"`           // License header above `package ...`, which is not a package documentation.
+	IsGenerated      bool       `json:"is_generated,omitempty"`      // File has `// Code generated ... DO NOT EDIT.` comment.
+
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"` // Problems, which were found during parsing, but did not stop it.
+
+	// FileSet, which was used for parsing. It is set by loaders, which parse sources by themselves.
+	FileSet *token.FileSet `json:"-"`
+}
+
+// Position returns position in the source for pos, which was received from parsed entities.
+// If FileSet is not set, zero position is returned.
+func (f File) Position(pos token.Pos) token.Position {
+	if f.FileSet == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	return f.FileSet.Position(pos)
 }
 
 func (f File) HasPackage(packageName string) bool {
@@ -27,3 +49,61 @@ func (f File) HasPackage(packageName string) bool {
 	}
 	return false
 }
+
+// Returns top-level declaration of the file by its name or nil, if it was not found.
+// Methods are not returned, because their names are not unique.
+func (f File) FindDecl(name string) Decl {
+	for i := range f.Structures {
+		if f.Structures[i].Name == name {
+			return &f.Structures[i]
+		}
+	}
+	for i := range f.Interfaces {
+		if f.Interfaces[i].Name == name {
+			return &f.Interfaces[i]
+		}
+	}
+	for i := range f.Types {
+		if f.Types[i].Name == name {
+			return &f.Types[i]
+		}
+	}
+	for i := range f.Functions {
+		if f.Functions[i].Name == name {
+			return &f.Functions[i]
+		}
+	}
+	for i := range f.Constants {
+		if f.Constants[i].Name == name {
+			return &f.Constants[i]
+		}
+	}
+	for i := range f.Vars {
+		if f.Vars[i].Name == name {
+			return &f.Vars[i]
+		}
+	}
+	return nil
+}
+
+// RequiredImports returns imports, which are needed by types of the declarations: struct fields, type parameters,
+// arguments and results of functions and methods, embedded interfaces, map keys and values and so on.
+// Imports are deduplicated by pointers and returned in order of their first usage, so package, which is imported
+// with several aliases, is returned once for each used alias.
+// Returned pointers are the same, as in TImport of the types, so aliases are kept.
+// Methods, which are linked to structures and types, are not visited, pass them explicitly if they are needed.
+func (f File) RequiredImports(decls ...Decl) []*Import {
+	var (
+		imports []*Import
+		seen    = make(map[*Import]bool)
+	)
+	for _, decl := range decls {
+		WalkDeclTypes(decl, func(t Type) {
+			if x, ok := t.(TImport); ok && x.Import != nil && !seen[x.Import] {
+				seen[x.Import] = true
+				imports = append(imports, x.Import)
+			}
+		})
+	}
+	return imports
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns hash of the exported API of the file: package name, exported constants, variables, functions,
+// types with their exported fields, tags and methods, and all methods of exported interfaces.
+// Docs, comments, positions, unexported declarations, names of arguments, order of declarations and aliases of imports
+// do not change it, so generators may compare fingerprints and skip work, when nothing relevant has changed.
+func (f File) Fingerprint() string {
+	lines := []string{"package " + f.Name}
+	for _, c := range f.Constants {
+		if token.IsExported(c.Name) {
+			lines = append(lines, "const "+c.Name+" "+fingerprintType(c.Type))
+		}
+	}
+	for _, v := range f.Vars {
+		if token.IsExported(v.Name) {
+			lines = append(lines, "var "+v.Name+" "+fingerprintType(v.Type))
+		}
+	}
+	for _, i := range f.Interfaces {
+		if token.IsExported(i.Name) {
+			lines = append(lines, "type "+i.Name+fingerprintTypeParams(i.TypeParams)+" "+fingerprintInterface(i))
+		}
+	}
+	for _, s := range f.Structures {
+		if token.IsExported(s.Name) {
+			lines = append(lines, "type "+s.Name+fingerprintTypeParams(s.TypeParams)+" "+fingerprintStruct(s))
+		}
+	}
+	for _, t := range f.Types {
+		if token.IsExported(t.Name) {
+			lines = append(lines, "type "+t.Name+fingerprintTypeParams(t.TypeParams)+" "+fingerprintType(t.Type))
+		}
+	}
+	for _, fn := range f.Functions {
+		if token.IsExported(fn.Name) {
+			lines = append(lines, "func "+fn.Name+fingerprintTypeParams(fn.TypeParams)+fingerprintSignature(fn))
+		}
+	}
+	for _, m := range f.Methods {
+		receiver := TypeName(m.Receiver.Type)
+		if token.IsExported(m.Name) && receiver != nil && token.IsExported(*receiver) {
+			lines = append(lines, "func ("+fingerprintType(m.Receiver.Type)+") "+m.Name+fingerprintSignature(m.Function))
+		}
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns string of type, where packages are qualified by quoted import paths instead of aliases.
+func fingerprintType(t Type) string {
+	if t == nil {
+		return ""
+	}
+	return MapType(t, func(t Type) Type {
+		switch x := t.(type) {
+		case TImport:
+			if x.Import != nil {
+				x.Import = &Import{Base: Base{Name: strconv.Quote(x.Import.Package)}, Package: x.Import.Package, Kind: ImportAliased}
+			}
+			return x
+		case Struct:
+			return TName{TypeName: fingerprintStruct(x)}
+		case TInterface:
+			if x.Interface != nil {
+				return TName{TypeName: fingerprintInterface(*x.Interface)}
+			}
+		case *Function:
+			if x != nil {
+				return TName{TypeName: "func" + fingerprintSignature(*x)}
+			}
+		}
+		return t
+	}).String()
+}
+
+func fingerprintTypes(vars []Variable) string {
+	strs := make([]string, len(vars))
+	for i := range vars {
+		strs[i] = fingerprintType(vars[i].Type)
+	}
+	return strings.Join(strs, ", ")
+}
+
+func fingerprintTypeParams(params []Variable) string {
+	if len(params) == 0 {
+		return ""
+	}
+	strs := make([]string, len(params))
+	for i := range params {
+		strs[i] = params[i].Name + " " + fingerprintType(params[i].Type)
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+func fingerprintSignature(f Function) string {
+	return "(" + fingerprintTypes(f.Args) + ") (" + fingerprintTypes(f.Results) + ")"
+}
+
+func fingerprintStruct(s Struct) string {
+	var fields []string
+	for _, field := range s.Fields {
+		name := field.Name
+		if name == "" {
+			if typeName := TypeName(field.Type); typeName != nil {
+				name = *typeName
+			}
+		}
+		if token.IsExported(name) {
+			fields = append(fields, field.Name+" "+fingerprintType(field.Type)+" "+field.RawTags)
+		}
+	}
+	return "struct {" + strings.Join(fields, "; ") + "}"
+}
+
+func fingerprintInterface(i Interface) string {
+	var methods []string
+	for _, m := range i.Methods {
+		if m != nil {
+			methods = append(methods, m.Name+fingerprintSignature(*m))
+		}
+	}
+	for _, embedded := range i.Interfaces {
+		methods = append(methods, fingerprintType(embedded.Type))
+	}
+	sort.Strings(methods)
+	return "interface {" + strings.Join(methods, "; ") + "}"
+}
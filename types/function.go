@@ -7,8 +7,9 @@ import (
 
 type Function struct {
 	Base
-	Args    []Variable `json:"args,omitempty"`
-	Results []Variable `json:"results,omitempty"`
+	TypeParams []Variable `json:"type_params,omitempty"`
+	Args       []Variable `json:"args,omitempty"`
+	Results    []Variable `json:"results,omitempty"`
 }
 
 type Method struct {
@@ -24,7 +25,7 @@ func (f Function) funcStr() string {
 	for _, res := range f.Results {
 		results = append(results, res.String())
 	}
-	return fmt.Sprintf("%s(%s) (%s)", f.Name, strings.Join(args, ", "), strings.Join(results, ", "))
+	return fmt.Sprintf("%s%s(%s) (%s)", f.Name, typeParamsStr(f.TypeParams), strings.Join(args, ", "), strings.Join(results, ", "))
 }
 
 func (f Function) String() string {
@@ -44,3 +45,14 @@ func (f Method) String() string {
 func (f Method) GoString() string {
 	return f.String()
 }
+
+func typeParamsStr(params []Variable) string {
+	if len(params) == 0 {
+		return ""
+	}
+	strs := make([]string, len(params))
+	for i := range params {
+		strs[i] = params[i].String()
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
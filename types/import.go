@@ -2,9 +2,32 @@ package types
 
 import "fmt"
 
+// ImportKind is a kind of import spec.
+type ImportKind string
+
+const (
+	ImportNormal  ImportKind = "normal"  // import "fmt"
+	ImportAliased ImportKind = "aliased" // import f "fmt"
+	ImportDot     ImportKind = "dot"     // import . "fmt"
+	ImportBlank   ImportKind = "blank"   // import _ "fmt"
+	ImportCgo     ImportKind = "cgo"     // import "C"
+)
+
 type Import struct {
-	Base
-	Package string `json:"package,omitempty"`
+	Base                // `Import.Name` is a name as it is written in the file: alias, `.`, `_` or name of package.
+	Package  string     `json:"package,omitempty"`
+	Kind     ImportKind `json:"kind,omitempty"`
+	RealName string     `json:"real_name,omitempty"` // Name from `package ...` clause of imported package.
+	Guessed  bool       `json:"guessed,omitempty"`   // Sources of package were not found, RealName is guessed from import path.
+}
+
+// LocalName returns name, which qualifies identifiers of imported package in the file.
+// Dot and blank imports have no local name.
+func (i Import) LocalName() string {
+	if i.Kind == ImportDot || i.Kind == ImportBlank || i.Name == "." || i.Name == "_" {
+		return ""
+	}
+	return i.Name
 }
 
 func (i Import) String() string {
@@ -7,8 +7,9 @@ import (
 
 type Interface struct {
 	Base
+	TypeParams []Variable  `json:"type_params,omitempty"`
 	Methods    []*Function `json:"methods,omitempty"`    // List of functions (methods) of the interface.
-	Interfaces []Variable  `json:"interfaces,omitempty"` // List of embedded interfaces.
+	Interfaces []Variable  `json:"interfaces,omitempty"` // List of embedded interfaces and type unions.
 }
 
 func (i Interface) String() string {
@@ -20,7 +21,7 @@ func (i Interface) String() string {
 	for k, m := range i.Interfaces {
 		methods[n+k] = m.String()
 	}
-	return fmt.Sprintf("type %s interface {\n\t%s\n}", i.Name, strings.Join(methods, "\n\t"))
+	return fmt.Sprintf("type %s%s interface {\n\t%s\n}", i.Name, typeParamsStr(i.TypeParams), strings.Join(methods, "\n\t"))
 }
 
 func (i Interface) GoString() string {
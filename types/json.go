@@ -0,0 +1,12 @@
+package types
+
+// JSONField is a field of structure, as encoding/json encodes and decodes it.
+type JSONField struct {
+	Name      string      `json:"name"`                // Key of field in JSON object.
+	Index     []int       `json:"index"`               // Indexes of field in structure and embedded structures, as in reflect.StructField.
+	Field     StructField `json:"field"`               // Go field, for promoted fields it is a field of embedded structure.
+	Tagged    bool        `json:"tagged,omitempty"`    // Name is taken from `json` tag.
+	OmitEmpty bool        `json:"omitempty,omitempty"` // Field has `omitempty` option.
+	OmitZero  bool        `json:"omitzero,omitempty"`  // Field has `omitzero` option.
+	String    bool        `json:"string,omitempty"`    // Field has `string` option and its type is boolean, numeric or string.
+}
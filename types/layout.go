@@ -0,0 +1,21 @@
+package types
+
+// StructLayout is a memory layout of structure, as it is placed by compiler for some GOARCH.
+type StructLayout struct {
+	Size            int64         `json:"size"`
+	Align           int64         `json:"align"`
+	Fields          []FieldLayout `json:"fields,omitempty"`
+	Padding         int64         `json:"padding,omitempty"`          // Sum of all padding bytes, including trailing padding.
+	TrailingPadding int64         `json:"trailing_padding,omitempty"` // Padding after the last field.
+	OptimalOrder    []string      `json:"optimal_order,omitempty"`    // Names of fields in order with minimal size.
+	OptimalSize     int64         `json:"optimal_size"`               // Size of structure with fields in optimal order.
+}
+
+// FieldLayout is a placement of one field in structure.
+type FieldLayout struct {
+	Name    string `json:"name"` // Name of field, embedded fields are named by their types.
+	Offset  int64  `json:"offset"`
+	Size    int64  `json:"size"`
+	Align   int64  `json:"align"`
+	Padding int64  `json:"padding,omitempty"` // Padding bytes before field.
+}
@@ -2,13 +2,46 @@ package types
 
 import (
 	"fmt"
+	"go/token"
 	"strings"
 )
 
 type StructField struct {
 	Variable
 	Tags    map[string][]string `json:"tags,omitempty"`
-	RawTags string              `json:"raw,omitempty"` // Raw string from source.
+	TagList []Tag               `json:"tag_list,omitempty"` // Tags in order of the source, duplicated keys are kept.
+	RawTags string              `json:"raw,omitempty"`      // Raw string from source.
+	TagPos  token.Pos           `json:"-"`                  // Position of tag literal or NoPos, when field has no tag.
+	TypeEnd token.Pos           `json:"-"`                  // Position right after type of field, where tag literal may be placed.
+}
+
+// Tag is one `key:"value"` pair of struct field tag.
+type Tag struct {
+	Key     string    `json:"key"`
+	Name    string    `json:"name,omitempty"`    // Part of value before the first comma.
+	Options []string  `json:"options,omitempty"` // Parts of value after the first comma.
+	Value   string    `json:"value,omitempty"`   // Unquoted value as it is in the tag.
+	Pos     token.Pos `json:"-"`                 // Position of the key in the FileSet, which was used for parsing.
+}
+
+// Returns first tag with provided key or nil.
+func (f StructField) Tag(key string) *Tag {
+	for i := range f.TagList {
+		if f.TagList[i].Key == key {
+			return &f.TagList[i]
+		}
+	}
+	return nil
+}
+
+// Checks that tag has an option.
+func (t Tag) HasOption(option string) bool {
+	for _, o := range t.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
 }
 
 func (f StructField) String() string {
@@ -17,8 +50,9 @@ func (f StructField) String() string {
 
 type Struct struct {
 	Base
-	Fields  []StructField `json:"fields,omitempty"`
-	Methods []*Method     `json:"methods,omitempty"`
+	TypeParams []Variable    `json:"type_params,omitempty"`
+	Fields     []StructField `json:"fields,omitempty"`
+	Methods    []*Method     `json:"methods,omitempty"`
 }
 
 func (s Struct) t() { return }
@@ -37,7 +71,7 @@ func stringFields(fields []StructField) string {
 }
 
 func (s Struct) String() string {
-	return fmt.Sprintf("%s struct {%s}", s.Name, stringFields(s.Fields))
+	return fmt.Sprintf("%s%s struct {%s}", s.Name, typeParamsStr(s.TypeParams), stringFields(s.Fields))
 }
 
 func (s Struct) IsEmpty() bool {
@@ -40,12 +40,20 @@ func (m TMap) String() string {
 
 type TName struct {
 	TypeName string `json:"type_name,omitempty"`
+	TypeArgs []Type `json:"type_args,omitempty"` // Type arguments of instantiated generic type, like `int` in `List[int]`.
 }
 
 func (i TName) t() { return }
 
 func (i TName) String() string {
-	return i.TypeName
+	if len(i.TypeArgs) == 0 {
+		return i.TypeName
+	}
+	args := make([]string, len(i.TypeArgs))
+	for k := range i.TypeArgs {
+		args[k] = i.TypeArgs[k].String()
+	}
+	return i.TypeName + "[" + strings.Join(args, ", ") + "]"
 }
 
 func (i TName) NextType() Type {
@@ -72,10 +80,11 @@ func (i TPointer) NextType() Type {
 }
 
 type TArray struct {
-	ArrayLen   int  `json:"array_len,omitempty"`
-	IsSlice    bool `json:"is_slice,omitempty"` // [] declaration
-	IsEllipsis bool `json:"is_ellipsis,omitempty"`
-	Next       Type `json:"next,omitempty"`
+	ArrayLen   int    `json:"array_len,omitempty"`
+	IsSlice    bool   `json:"is_slice,omitempty"` // [] declaration
+	IsEllipsis bool   `json:"is_ellipsis,omitempty"`
+	LenExpr    string `json:"len_expr,omitempty"` // Length, which is a constant expression, like `N` in `[N]byte`. ArrayLen is 0 then.
+	Next       Type   `json:"next,omitempty"`
 }
 
 func (i TArray) t() { return }
@@ -86,6 +95,8 @@ func (i TArray) String() string {
 		str += "..."
 	} else if i.IsSlice {
 		str += "[]"
+	} else if i.LenExpr != "" {
+		str += "[" + i.LenExpr + "]"
 	} else {
 		str += "[" + strconv.Itoa(i.ArrayLen) + "]"
 	}
@@ -108,8 +119,8 @@ func (i TImport) t() { return }
 
 func (i TImport) String() string {
 	str := ""
-	if i.Import != nil {
-		str += i.Import.Name + "."
+	if i.Import != nil && i.Import.LocalName() != "" {
+		str += i.Import.LocalName() + "."
 	}
 	if i.Next != nil {
 		str += i.Next.String()
@@ -170,3 +181,29 @@ func (c TChan) String() string {
 	}
 	return str
 }
+
+// TUnion used only in type constraints like `~int | string`.
+type TUnion struct {
+	Terms []TTerm `json:"terms,omitempty"`
+}
+
+// TTerm is a term of type union, `~int` or `string`.
+type TTerm struct {
+	Tilde bool `json:"tilde,omitempty"`
+	Type  Type `json:"type,omitempty"`
+}
+
+func (u TUnion) t() { return }
+
+func (u TUnion) String() string {
+	terms := make([]string, len(u.Terms))
+	for i := range u.Terms {
+		if u.Terms[i].Tilde {
+			terms[i] = "~"
+		}
+		if u.Terms[i].Type != nil {
+			terms[i] += u.Terms[i].Type.String()
+		}
+	}
+	return strings.Join(terms, " | ")
+}
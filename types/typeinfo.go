@@ -0,0 +1,13 @@
+package types
+
+import gotypes "go/types"
+
+// TypeInfo is an information about entity, received from go/types type checker.
+// It is filled only when parsing with astra.CheckTypes option.
+type TypeInfo struct {
+	Object      gotypes.Object `json:"-"`                       // Object of the entity from type checker.
+	Type        string         `json:"type,omitempty"`          // Exact type of the entity. For variables without explicit type it is a type of initializer.
+	Underlying  string         `json:"underlying,omitempty"`    // Underlying type of the entity's type.
+	Package     string         `json:"package,omitempty"`       // Path of the package, where named type of the entity is declared.
+	IsTypeParam bool           `json:"is_type_param,omitempty"` // True, when type of the entity is a type parameter.
+}
@@ -2,7 +2,8 @@ package types
 
 type Variable struct {
 	Base
-	Type Type `json:"type,omitempty"`
+	Type     Type      `json:"type,omitempty"`
+	TypeInfo *TypeInfo `json:"type_info,omitempty"`
 }
 
 // String representation of variable without docs
@@ -0,0 +1,254 @@
+package types
+
+// WalkBases calls fn for Base of the file and each its top-level declaration, interface method,
+// struct field, function argument and result, method receiver.
+// Embedded Base of methods, which are linked to structures, are visited once.
+func WalkBases(f *File, fn func(*Base)) {
+	fn(&f.Base)
+	for _, imp := range f.Imports {
+		if imp != nil {
+			fn(&imp.Base)
+		}
+	}
+	walkVariables(f.Constants, fn)
+	walkVariables(f.Vars, fn)
+	for i := range f.Interfaces {
+		fn(&f.Interfaces[i].Base)
+		walkVariables(f.Interfaces[i].TypeParams, fn)
+		for _, m := range f.Interfaces[i].Methods {
+			if m != nil {
+				walkFunction(m, fn)
+			}
+		}
+		walkVariables(f.Interfaces[i].Interfaces, fn)
+	}
+	for i := range f.Structures {
+		fn(&f.Structures[i].Base)
+		walkVariables(f.Structures[i].TypeParams, fn)
+		for j := range f.Structures[i].Fields {
+			fn(&f.Structures[i].Fields[j].Base)
+		}
+	}
+	for i := range f.Functions {
+		walkFunction(&f.Functions[i], fn)
+	}
+	for i := range f.Methods {
+		walkFunction(&f.Methods[i].Function, fn)
+		fn(&f.Methods[i].Receiver.Base)
+	}
+	for i := range f.Types {
+		fn(&f.Types[i].Base)
+		walkVariables(f.Types[i].TypeParams, fn)
+	}
+}
+
+func walkFunction(f *Function, fn func(*Base)) {
+	fn(&f.Base)
+	walkVariables(f.TypeParams, fn)
+	walkVariables(f.Args, fn)
+	walkVariables(f.Results, fn)
+}
+
+func walkVariables(vars []Variable, fn func(*Base)) {
+	for i := range vars {
+		fn(&vars[i].Base)
+	}
+}
+
+// MapType rebuilds type with types, which are returned by fn. Children of composite types are mapped first,
+// then fn is called for the type with mapped children. Qualified types are passed to fn as TImport,
+// their names are not passed separately, but type arguments are.
+func MapType(t Type, fn func(Type) Type) Type {
+	switch x := t.(type) {
+	case nil:
+		return nil
+	case TName:
+		x.TypeArgs = mapTypes(x.TypeArgs, fn)
+		return fn(x)
+	case TImport:
+		if name, ok := x.Next.(TName); ok {
+			name.TypeArgs = mapTypes(name.TypeArgs, fn)
+			x.Next = name
+		} else {
+			x.Next = MapType(x.Next, fn)
+		}
+		return fn(x)
+	case TPointer:
+		x.Next = MapType(x.Next, fn)
+		return fn(x)
+	case TArray:
+		x.Next = MapType(x.Next, fn)
+		return fn(x)
+	case TEllipsis:
+		x.Next = MapType(x.Next, fn)
+		return fn(x)
+	case TChan:
+		x.Next = MapType(x.Next, fn)
+		return fn(x)
+	case TMap:
+		x.Key, x.Value = MapType(x.Key, fn), MapType(x.Value, fn)
+		return fn(x)
+	case TUnion:
+		terms := make([]TTerm, len(x.Terms))
+		for i := range x.Terms {
+			terms[i] = TTerm{Tilde: x.Terms[i].Tilde, Type: MapType(x.Terms[i].Type, fn)}
+		}
+		x.Terms = terms
+		return fn(x)
+	case TInterface:
+		if x.Interface != nil {
+			iface := *x.Interface
+			mapInterface(&iface, fn)
+			x.Interface = &iface
+		}
+		return fn(x)
+	case Struct:
+		mapStruct(&x, fn)
+		return fn(x)
+	case *Struct:
+		s := *x
+		mapStruct(&s, fn)
+		return fn(&s)
+	case Function:
+		mapFunction(&x, fn)
+		return fn(x)
+	case *Function:
+		f := *x
+		mapFunction(&f, fn)
+		return fn(&f)
+	}
+	return fn(t)
+}
+
+// MapTypes replaces types of all declarations of file with types, which are returned by fn, see MapType.
+func (f *File) MapTypes(fn func(Type) Type) {
+	mapVariables(f.Constants, fn)
+	mapVariables(f.Vars, fn)
+	for i := range f.Interfaces {
+		mapVariables(f.Interfaces[i].TypeParams, fn)
+		mapInterface(&f.Interfaces[i], fn)
+	}
+	for i := range f.Structures {
+		mapStruct(&f.Structures[i], fn)
+	}
+	for i := range f.Functions {
+		mapFunction(&f.Functions[i], fn)
+	}
+	for i := range f.Methods {
+		mapFunction(&f.Methods[i].Function, fn)
+		f.Methods[i].Receiver.Type = MapType(f.Methods[i].Receiver.Type, fn)
+	}
+	for i := range f.Types {
+		mapVariables(f.Types[i].TypeParams, fn)
+		f.Types[i].Type = MapType(f.Types[i].Type, fn)
+	}
+}
+
+func mapTypes(ts []Type, fn func(Type) Type) []Type {
+	if ts == nil {
+		return nil
+	}
+	mapped := make([]Type, len(ts))
+	for i := range ts {
+		mapped[i] = MapType(ts[i], fn)
+	}
+	return mapped
+}
+
+func mapVariables(vars []Variable, fn func(Type) Type) {
+	for i := range vars {
+		vars[i].Type = MapType(vars[i].Type, fn)
+	}
+}
+
+func mapFunction(f *Function, fn func(Type) Type) {
+	f.TypeParams = append([]Variable(nil), f.TypeParams...)
+	f.Args = append([]Variable(nil), f.Args...)
+	f.Results = append([]Variable(nil), f.Results...)
+	mapVariables(f.TypeParams, fn)
+	mapVariables(f.Args, fn)
+	mapVariables(f.Results, fn)
+}
+
+func mapStruct(s *Struct, fn func(Type) Type) {
+	s.TypeParams = append([]Variable(nil), s.TypeParams...)
+	mapVariables(s.TypeParams, fn)
+	fields := make([]StructField, len(s.Fields))
+	copy(fields, s.Fields)
+	for i := range fields {
+		fields[i].Type = MapType(fields[i].Type, fn)
+	}
+	if s.Fields != nil {
+		s.Fields = fields
+	}
+}
+
+func mapInterface(iface *Interface, fn func(Type) Type) {
+	methods := make([]*Function, len(iface.Methods))
+	for i, m := range iface.Methods {
+		if m == nil {
+			continue
+		}
+		method := *m
+		mapFunction(&method, fn)
+		methods[i] = &method
+	}
+	if iface.Methods != nil {
+		iface.Methods = methods
+	}
+	iface.Interfaces = append([]Variable(nil), iface.Interfaces...)
+	mapVariables(iface.Interfaces, fn)
+}
+
+// WalkDeclTypes calls fn for each type of declaration and its children in the same order, as MapType does it:
+// struct fields, type parameters, arguments and results of functions, interface methods and embedded interfaces,
+// receivers of methods. Declaration is not changed. Methods, linked to structures and types, are not visited.
+func WalkDeclTypes(decl Decl, fn func(Type)) {
+	visit := func(t Type) Type {
+		fn(t)
+		return t
+	}
+	switch d := decl.(type) {
+	case Struct:
+		MapType(d, visit)
+	case *Struct:
+		MapType(*d, visit)
+	case Interface:
+		walkInterfaceTypes(d, visit)
+	case *Interface:
+		walkInterfaceTypes(*d, visit)
+	case FileType:
+		walkFileTypeTypes(d, visit)
+	case *FileType:
+		walkFileTypeTypes(*d, visit)
+	case Method:
+		walkMethodTypes(d, visit)
+	case *Method:
+		walkMethodTypes(*d, visit)
+	case Function:
+		MapType(d, visit)
+	case *Function:
+		MapType(*d, visit)
+	case Variable:
+		MapType(d.Type, visit)
+	case *Variable:
+		MapType(d.Type, visit)
+	}
+}
+
+// Helpers below work on copies, so passed declarations are not changed.
+
+func walkInterfaceTypes(iface Interface, fn func(Type) Type) {
+	mapVariables(append([]Variable(nil), iface.TypeParams...), fn)
+	mapInterface(&iface, fn)
+}
+
+func walkFileTypeTypes(t FileType, fn func(Type) Type) {
+	mapVariables(append([]Variable(nil), t.TypeParams...), fn)
+	MapType(t.Type, fn)
+}
+
+func walkMethodTypes(m Method, fn func(Type) Type) {
+	MapType(m.Receiver.Type, fn)
+	mapFunction(&m.Function, fn)
+}
@@ -3,10 +3,12 @@ package astra
 import (
 	"fmt"
 	"go/ast"
+	"go/build"
 	astparser "go/parser"
 	"go/token"
+	"io"
+	"io/fs"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -14,23 +16,74 @@ import (
 )
 
 // Opens and parses file by name and return information about it.
+// Bodies of functions are not parsed without KeepFuncBodies option, so syntax errors inside of them are not reported,
+// only lexical ones, like unterminated string or invalid character, are.
 func ParseFile(filename string, options ...Option) (*types.File, error) {
+	return parseFile(nil, filename, options...)
+}
+
+// Parses file, which contents are taken from overlay, when it has the file.
+func parseFile(ov overlay, filename string, options ...Option) (*types.File, error) {
 	path, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, fmt.Errorf("can not filepath.Abs: %v", err)
 	}
+	src, err := ov.readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error when parse file: %v", err)
+	}
+	return parseSourceCached(ov, path, src, options...)
+}
+
+// ParseSource parses file from memory. Name is used in positions and its directory is used to find names of
+// imported packages and, with CheckTypes option, to resolve imports, so it should be a path, where the file is placed or will be placed.
+func ParseSource(name string, src []byte, options ...Option) (*types.File, error) {
+	return parseSourceCached(nil, name, src, options...)
+}
+
+// Parses file from memory with cache, imports are resolved with overlay.
+func parseSourceCached(ov overlay, name string, src []byte, options ...Option) (*types.File, error) {
+	r := nameResolver(filepath.Dir(name), ov)
+	return parseCached(concatOptions(options), r, []string{name}, [][]byte{src}, func() (*types.File, error) {
+		return parseSource(ov, name, src, r, options...)
+	})
+}
+
+// Parses file from memory, names of imported packages are found by r.
+func parseSource(ov overlay, name string, src []byte, r *Resolver, options ...Option) (*types.File, error) {
 	fset := token.NewFileSet()
-	tree, err := astparser.ParseFile(fset, path, nil, astparser.ParseComments)
+	tree, err := parseGoFile(fset, name, src, astparser.ParseComments, concatOptions(options))
 	if err != nil {
 		return nil, fmt.Errorf("error when parse file: %v", err)
 	}
-	info, err := ParseAstFile(tree, options...)
+	attachParamComments(fset, tree)
+	info, err := parseAstFile(tree, r, options...)
 	if err != nil {
 		return nil, fmt.Errorf("error when parsing info from file: %v", err)
 	}
+	info.FileSet = fset
+	if concatOptions(options).check(CheckTypes) {
+		err = checkAndAnnotate(info, fset, []*ast.File{tree}, filepath.Dir(name), ov, options...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = resolveDotImports(info, filepath.Dir(name), ov, options...)
+	if err != nil {
+		return nil, err
+	}
 	return info, nil
 }
 
+// ParseReader reads all sources from r and parses them, see ParseSource.
+func ParseReader(name string, r io.Reader, options ...Option) (*types.File, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("can not read source: %v", err)
+	}
+	return ParseSource(name, src, options...)
+}
+
 // Merges parsed files to one. Helpful, when you need full information about package.
 func MergeFiles(files []*types.File) (*types.File, error) {
 	targetFile := &types.File{}
@@ -57,7 +110,8 @@ func MergeFiles(files []*types.File) (*types.File, error) {
 }
 
 // Parses all .go files from directory.
-// Deprecated: use GetPackage instead
+//
+// Deprecated: use GetPackage instead.
 func ParsePackage(path string, options ...Option) ([]*types.File, error) {
 	p, err := filepath.Abs(path)
 	if err != nil {
@@ -84,42 +138,158 @@ func ParsePackage(path string, options ...Option) ([]*types.File, error) {
 	return parsedFiles, nil
 }
 
+// Parses all .go files from directory and merges them to one file.
+// Syntax errors inside of function bodies are reported only with KeepFuncBodies option, see ParseFile.
 func GetPackage(path string, options ...Option) (*types.File, error) {
+	return getPackage(nil, path, options...)
+}
+
+// Parses all .go files from directory and overlay and merges them to one file.
+func getPackage(ov overlay, path string, options ...Option) (*types.File, error) {
 	p, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("can not filepath.Abs: %v", err)
 	}
+	parse := func() (*types.File, error) {
+		fset := token.NewFileSet()
+		pkgs, err := parseDir(diskFS{overlay: ov}, fset, p, nil, astparser.ParseComments, concatOptions(options))
+		if err != nil {
+			return nil, fmt.Errorf("can not parse dir: %v", err)
+		}
+		return parsePackages(fset, pkgs, p, ov, options...)
+	}
+	if currentCacheDir() == "" {
+		return parse()
+	}
+	names, sources, err := readGoFiles(ov, p)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse dir: %v", err)
+	}
+	return parseCached(concatOptions(options), nameResolver(p, ov), names, sources, parse)
+}
+
+// Reads all .go files from directory and overlay.
+func readGoFiles(ov overlay, dir string) (names []string, sources [][]byte, err error) {
+	infos, err := ov.readDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+			continue
+		}
+		name := filepath.Join(dir, info.Name())
+		src, err := ov.readFile(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, name)
+		sources = append(sources, src)
+	}
+	return names, sources, nil
+}
+
+// GetPackageFS parses all non-test .go files from directory of fsys, which satisfy build constraints,
+// and merges them to one file. Dir is a slash-separated path, as fs.FS requires.
+// Names of imported packages and, with CheckTypes option, imports are resolved from the working directory.
+func GetPackageFS(fsys fs.FS, dir string, options ...Option) (*types.File, error) {
+	sys := ioFS{fsys: fsys}
 	fset := token.NewFileSet()
-	pkgs, err := astparser.ParseDir(fset, p, nil, astparser.ParseComments)
+	pkgs, err := parseDir(sys, fset, dir, sourceFilterOf(sys, dir), astparser.ParseComments, concatOptions(options))
 	if err != nil {
 		return nil, fmt.Errorf("can not parse dir: %v", err)
 	}
+	return parsePackages(fset, pkgs, ".", nil, options...)
+}
+
+// Merges files of the only package and parses it. Names of imported packages and, when types should be checked,
+// imports are resolved from dir with overlay.
+func parsePackages(fset *token.FileSet, pkgs map[string]*ast.Package, dir string, ov overlay, options ...Option) (*types.File, error) {
 	if len(pkgs) > 1 {
 		return nil, fmt.Errorf("unexpected number of packages: expect 1, found %d", len(pkgs))
 	}
 	for _, pkg := range pkgs {
-		f := ast.MergePackageFiles(pkg, ast.FilterUnassociatedComments|ast.FilterFuncDuplicates|ast.FilterImportDuplicates)
-		return ParseAstFile(f, options...)
+		astFiles := packageFiles(pkg)
+		for _, file := range astFiles {
+			attachParamComments(fset, file)
+		}
+		f, err := parseMergedPackage(pkg, astFiles, nameResolver(dir, ov), options...)
+		if err != nil {
+			return nil, err
+		}
+		f.FileSet = fset
+		if concatOptions(options).check(CheckTypes) {
+			err = checkAndAnnotate(f, fset, astFiles, dir, ov, options...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = resolveDotImports(f, dir, ov, options...)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
 	}
 	return nil, fmt.Errorf("unexpected number of packages: expect 1, found 0")
 }
 
-func ResolvePackagePath(outPath string) (string, error) {
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		return "", ErrGoPathIsEmpty
+// Merges files of package to one file and parses it. Floating comments are collected from every file,
+// license is taken from the first file, which has it, package is generated, when all its files are generated.
+// Names of imported packages are found by r.
+func parseMergedPackage(pkg *ast.Package, astFiles []*ast.File, r *Resolver, options ...Option) (*types.File, error) {
+	f, err := parseAstFile(ast.MergePackageFiles(pkg, ast.FilterFuncDuplicates|ast.FilterImportDuplicates), r, options...)
+	if err != nil {
+		return nil, err
+	}
+	opt := concatOptions(options)
+	f.FloatingComments, f.License, f.IsGenerated = nil, nil, len(astFiles) > 0
+	for _, file := range astFiles {
+		f.FloatingComments = append(f.FloatingComments, parseFloatingComments(file, opt)...)
+		if f.License == nil {
+			f.License = parseLicense(file, opt)
+		}
+		f.IsGenerated = f.IsGenerated && ast.IsGenerated(file)
 	}
+	return f, nil
+}
 
-	absOutPath, err := filepath.Abs(filepath.Dir(outPath))
+// ResolvePackagePath returns import path of package, which contains file outPath, see ImportPath.
+func ResolvePackagePath(outPath string) (string, error) {
+	return ImportPath(filepath.Dir(outPath))
+}
+
+// ImportPath returns import path of package in dir. Directory is mapped with the nearest go.mod,
+// so packages of nested modules get paths of their modules. Packages from vendor directories get paths without vendor prefix.
+// Directories of standard library are mapped with GOROOT, directories outside of modules are mapped with GOPATH.
+func ImportPath(dir string) (string, error) {
+	return importPath(nil, dir)
+}
+
+// Returns import path of package in dir, go.mod files are searched in overlay too.
+func importPath(ov overlay, dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("can not filepath.Abs: %v", err)
+	}
+	if rel, ok := relPath(filepath.Join(build.Default.GOROOT, "src"), abs); ok && rel != "" {
+		return trimVendor(rel), nil
+	}
+	mod, err := findGoMod(ov, abs)
 	if err != nil {
 		return "", err
 	}
-	for _, path := range strings.Split(gopath, ":") {
-		gopathSrc := filepath.Join(path, "src")
-		if !strings.HasPrefix(absOutPath, gopathSrc) {
-			continue
+	if mod != nil {
+		if importPath, ok := mod.importPath(abs); ok {
+			return importPath, nil
+		}
+	}
+	if build.Default.GOPATH == "" {
+		return "", ErrGoPathIsEmpty
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		if rel, ok := relPath(filepath.Join(gopath, "src"), abs); ok && rel != "" {
+			return trimVendor(rel), nil
 		}
-		return absOutPath[len(gopathSrc)+1:], nil
 	}
 	return "", ErrNotInGoPath
 }
@@ -147,3 +317,162 @@ func parseCommentFromSources(opt Option, groups ...*ast.CommentGroup) []string {
 	}
 	return mergeStringSlices(temp...)
 }
+
+// Constructs comments model from comment groups of declaration.
+// Group is a documentation of declarations group, it is nil for not grouped declarations.
+func parseCommentsModel(opt Option, group, doc, trailing *ast.CommentGroup) *types.Comments {
+	if opt.check(IgnoreComments) || (group == nil && doc == nil && trailing == nil) {
+		return nil
+	}
+	return &types.Comments{
+		Group:    parseComment(group),
+		Doc:      parseComment(doc),
+		Trailing: parseComment(trailing),
+	}
+}
+
+func parseComment(group *ast.CommentGroup) *types.Comment {
+	if group == nil {
+		return nil
+	}
+	c := &types.Comment{}
+	for _, comment := range group.List {
+		c.Raw = append(c.Raw, comment.Text)
+		c.Positions = append(c.Positions, comment.Slash)
+	}
+	return c
+}
+
+// Constructs comments model for spec of general declaration.
+// Documentation of not grouped declaration, e.g. `type X int`, is placed by go/ast to GenDecl,
+// but it is own documentation of spec.
+func parseSpecComments(opt Option, decl *ast.GenDecl, doc, trailing *ast.CommentGroup) *types.Comments {
+	if !decl.Lparen.IsValid() && doc == nil {
+		return parseCommentsModel(opt, nil, decl.Doc, trailing)
+	}
+	return parseCommentsModel(opt, decl.Doc, doc, trailing)
+}
+
+// Attaches comments to fields of function parameters, results and type parameters, because go/parser does it
+// only for struct fields and interface methods. Comment group, which ends on the line before field, becomes its Doc,
+// comment group, which starts on the line where field ends, becomes its Comment.
+func attachParamComments(fset *token.FileSet, file *ast.File) {
+	if len(file.Comments) == 0 {
+		return
+	}
+	line := func(pos token.Pos) int {
+		return fset.Position(pos).Line
+	}
+	used := make(map[*ast.CommentGroup]bool)
+	attach := func(list *ast.FieldList) {
+		if list == nil || !list.Opening.IsValid() {
+			return
+		}
+		prevEnd := list.Opening
+		for i, field := range list.List {
+			nextStart := list.Closing
+			if i+1 < len(list.List) {
+				nextStart = list.List[i+1].Pos()
+			}
+			for _, group := range file.Comments {
+				switch {
+				case used[group]:
+				case field.Doc == nil && group.Pos() > prevEnd && group.End() < field.Pos() && line(group.End())+1 == line(field.Pos()):
+					field.Doc, used[group] = group, true
+				case field.Comment == nil && group.Pos() > field.End() && group.End() <= nextStart && line(group.Pos()) == line(field.End()):
+					field.Comment, used[group] = group, true
+				}
+			}
+			prevEnd = field.End()
+		}
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if funcType, ok := node.(*ast.FuncType); ok {
+			attach(funcType.TypeParams)
+			attach(funcType.Params)
+			attach(funcType.Results)
+		}
+		return true
+	})
+}
+
+// Returns comment groups, which are attached by parser to declarations, specs and fields.
+// Documentation of declarations group is not counted as attached, because it has no own entity.
+func attachedComments(file *ast.File) map[*ast.CommentGroup]bool {
+	attached := make(map[*ast.CommentGroup]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.GenDecl:
+			if !n.Lparen.IsValid() {
+				attached[n.Doc] = true
+			}
+		case *ast.FuncDecl:
+			attached[n.Doc] = true
+		case *ast.ImportSpec:
+			attached[n.Doc], attached[n.Comment] = true, true
+		case *ast.ValueSpec:
+			attached[n.Doc], attached[n.Comment] = true, true
+		case *ast.TypeSpec:
+			attached[n.Doc], attached[n.Comment] = true, true
+		case *ast.Field:
+			attached[n.Doc], attached[n.Comment] = true, true
+		}
+		return true
+	})
+	return attached
+}
+
+// Collects comments, which are not attached to the package clause or any declaration and
+// are placed outside of declarations: license headers, build constraints, separators between declarations.
+func parseFloatingComments(file *ast.File, opt Option) []*types.Comment {
+	if opt.check(IgnoreComments) {
+		return nil
+	}
+	attached := attachedComments(file)
+	var comments []*types.Comment
+	for _, group := range file.Comments {
+		if group == file.Doc || attached[group] || insideDecl(file, group) {
+			continue
+		}
+		comments = append(comments, parseComment(group))
+	}
+	return comments
+}
+
+// Checks that comment is placed inside declaration or is a documentation of declarations group.
+func insideDecl(file *ast.File, group *ast.CommentGroup) bool {
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Doc == group {
+			return true
+		}
+		if decl.Pos() <= group.Pos() && group.End() <= decl.End() {
+			return true
+		}
+	}
+	return false
+}
+
+var licenseMarkers = []string{"copyright", "license", "spdx-license-identifier"}
+
+// Returns first comment before package clause, which is not a package documentation and
+// looks like license header: it mentions copyright or license.
+func parseLicense(file *ast.File, opt Option) *types.Comment {
+	if opt.check(IgnoreComments) {
+		return nil
+	}
+	for _, group := range file.Comments {
+		if group.End() >= file.Package {
+			break
+		}
+		if group == file.Doc {
+			continue
+		}
+		text := strings.ToLower(group.Text())
+		for _, marker := range licenseMarkers {
+			if strings.Contains(text, marker) {
+				return parseComment(group)
+			}
+		}
+	}
+	return nil
+}